@@ -5,6 +5,7 @@ import (
 	"api-client/pkg/models"
 	"context"
 	"fmt"
+	"time"
 )
 
 var _ ClientInterface = Client{}
@@ -25,16 +26,27 @@ type ClientInterface interface {
 	DeleteBoardByID(ctx context.Context, boardID models.BoardID) (board models.Board, err error)
 	GetBoardByID(ctx context.Context, boardID models.BoardID) (board models.Board, err error)
 	UpdateBoardByID(ctx context.Context, boardID models.BoardID, name *string, owner *models.UserID) (board models.Board, err error)
+	GetBoardSynonyms(ctx context.Context, boardID models.BoardID) (groups [][]string, err error)
+	SetBoardSynonyms(ctx context.Context, boardID models.BoardID, groups [][]string) (err error)
+	GetFrequentQueries(ctx context.Context, boardID models.BoardID, since time.Time, limit int) (stats []models.QueryStat, err error)
+	GetFailedQueries(ctx context.Context, boardID models.BoardID, since time.Time, maxScore *float64, limit int) (stats []models.QueryStat, err error)
 	GetMediaByID(ctx context.Context, mediaID models.MediaID) (media models.Media, err error)
-	PutMediaByID(ctx context.Context, media models.Media, filename string) (err error)
-	ListMemes(ctx context.Context, offset, limit int, sortBy string) (boards []models.Meme, err error)
+	PutMediaByID(ctx context.Context, media models.Media, filename string, force bool) (err error)
+	ListMemes(ctx context.Context, offset, limit int, sortBy string, filter models.MemeFilter) (boards []models.Meme, err error)
+	ListBoardMemes(ctx context.Context, boardID models.BoardID, offset, limit int) (memes []models.Meme, err error)
 	PostMeme(ctx context.Context, boardID models.BoardID, filename string, dsc map[string]string) (meme models.Meme, err error)
 	DeleteMemeByID(ctx context.Context, memeID models.MemeID) (meme models.Meme, err error)
 	GetMemeByID(ctx context.Context, memeID models.MemeID) (meme models.Meme, err error)
+	PostMemeUsage(ctx context.Context, memeID models.MemeID, source string) (err error)
 	UpdateMemeByID(ctx context.Context, memeID models.MemeID, boardID *models.BoardID, filename *string, dsc *map[string]string) (meme models.Meme, err error)
-	SearchMemes(ctx context.Context, offset, limit int, general string) (memes []models.ScoredMeme, err error)
+	SearchMemes(ctx context.Context, offset, limit int, query models.SearchQuery) (page models.SearchPage, err error)
+	SearchBoardMemes(ctx context.Context, boardID models.BoardID, offset, limit int, query models.SearchQuery) (page models.SearchPage, err error)
+	SuggestQueries(ctx context.Context, prefix string, limit int) (sug models.Suggestions, err error)
+	SuggestDescription(ctx context.Context, memeID models.MemeID, limit int) (sugs []models.DescriptionSuggestion, err error)
+	SearchMemesByImage(ctx context.Context, offset, limit int, image []byte) (memes []models.ScoredMeme, err error)
 	SubscribeByBoardID(ctx context.Context, boardID models.BoardID) (err error)
 	UnsubscribeByBoardID(ctx context.Context, boardID models.BoardID) (err error)
+	SetSubscriptionWeight(ctx context.Context, boardID models.BoardID, weight float64) (err error)
 	GetUserByID(ctx context.Context, userID models.UserID) (user models.User, err error)
 }
 
@@ -235,6 +247,126 @@ func (c Client) GetBoardByID(ctx context.Context, boardID models.BoardID) (board
 	}
 }
 
+// GetBoardSynonyms implements ClientInterface.
+func (c Client) GetBoardSynonyms(ctx context.Context, boardID models.BoardID) (groups [][]string, err error) {
+	resp, err := c.api.GetBoardSynonymsWithResponse(ctx, apiclient.BoardId(boardID), c.middlewares()...)
+	if err != nil {
+		err = fmt.Errorf("can't request: %w", err)
+		return
+	}
+	switch resp.StatusCode() {
+	case 200:
+		groups = resp.JSON200.Groups
+		return
+	case 401:
+		err = models.ErrUnauthorized
+		return
+	case 403:
+		err = models.ErrForbidden
+		return
+	case 404:
+		err = models.ErrBoardNotFound
+		return
+	default:
+		err = fmt.Errorf("unexpected response %d: %s", resp.StatusCode(), string(resp.Body))
+		return
+	}
+}
+
+// SetBoardSynonyms implements ClientInterface.
+func (c Client) SetBoardSynonyms(ctx context.Context, boardID models.BoardID, groups [][]string) (err error) {
+	resp, err := c.api.SetBoardSynonymsWithResponse(ctx, apiclient.BoardId(boardID), apiclient.SetBoardSynonymsJSONRequestBody{Groups: groups}, c.middlewares()...)
+	if err != nil {
+		err = fmt.Errorf("can't request: %w", err)
+		return
+	}
+	switch resp.StatusCode() {
+	case 200:
+		return
+	case 400:
+		err = parseApiError(*resp.JSON400)
+		return
+	case 401:
+		err = models.ErrUnauthorized
+		return
+	case 403:
+		err = models.ErrForbidden
+		return
+	case 404:
+		err = models.ErrBoardNotFound
+		return
+	default:
+		err = fmt.Errorf("unexpected response %d: %s", resp.StatusCode(), string(resp.Body))
+		return
+	}
+}
+
+// GetFrequentQueries implements ClientInterface. Zero since uses the server default period.
+func (c Client) GetFrequentQueries(ctx context.Context, boardID models.BoardID, since time.Time, limit int) (stats []models.QueryStat, err error) {
+	req := &apiclient.GetFrequentQueriesParams{Limit: &limit}
+	if !since.IsZero() {
+		req.Since = &since
+	}
+	resp, err := c.api.GetFrequentQueriesWithResponse(ctx, apiclient.BoardId(boardID), req, c.middlewares()...)
+	if err != nil {
+		err = fmt.Errorf("can't request: %w", err)
+		return
+	}
+	switch resp.StatusCode() {
+	case 200:
+		stats = convertQueryStatsToModel(*resp.JSON200)
+		return
+	case 400:
+		err = parseApiError(*resp.JSON400)
+		return
+	case 401:
+		err = models.ErrUnauthorized
+		return
+	case 403:
+		err = models.ErrForbidden
+		return
+	case 404:
+		err = models.ErrBoardNotFound
+		return
+	default:
+		err = fmt.Errorf("unexpected response %d: %s", resp.StatusCode(), string(resp.Body))
+		return
+	}
+}
+
+// GetFailedQueries implements ClientInterface. Zero since uses the server default period.
+func (c Client) GetFailedQueries(ctx context.Context, boardID models.BoardID, since time.Time, maxScore *float64, limit int) (stats []models.QueryStat, err error) {
+	req := &apiclient.GetFailedQueriesParams{Limit: &limit, MaxScore: maxScore}
+	if !since.IsZero() {
+		req.Since = &since
+	}
+	resp, err := c.api.GetFailedQueriesWithResponse(ctx, apiclient.BoardId(boardID), req, c.middlewares()...)
+	if err != nil {
+		err = fmt.Errorf("can't request: %w", err)
+		return
+	}
+	switch resp.StatusCode() {
+	case 200:
+		stats = convertQueryStatsToModel(*resp.JSON200)
+		return
+	case 400:
+		err = parseApiError(*resp.JSON400)
+		return
+	case 401:
+		err = models.ErrUnauthorized
+		return
+	case 403:
+		err = models.ErrForbidden
+		return
+	case 404:
+		err = models.ErrBoardNotFound
+		return
+	default:
+		err = fmt.Errorf("unexpected response %d: %s", resp.StatusCode(), string(resp.Body))
+		return
+	}
+}
+
 // GetMediaByID implements ClientInterface.
 func (c Client) GetMediaByID(ctx context.Context, mediaID models.MediaID) (media models.Media, err error) {
 	resp, err := c.api.GetMediaByIDWithResponse(ctx, apiclient.MediaId(mediaID), c.middlewares()...)
@@ -342,8 +474,18 @@ func (c Client) ListBoards(ctx context.Context, offset int, limit int, sortBy st
 }
 
 // ListMemes implements ClientInterface.
-func (c Client) ListMemes(ctx context.Context, offset int, limit int, sortBy string) (memes []models.Meme, err error) {
-	req := &apiclient.ListMemesParams{Offset: &offset, Limit: &limit, SortBy: (*apiclient.ListMemesParamsSortBy)(&sortBy)}
+func (c Client) ListMemes(ctx context.Context, offset int, limit int, sortBy string, filter models.MemeFilter) (memes []models.Meme, err error) {
+	boards, mediaType, after, before, owner := filterParams(filter)
+	req := &apiclient.ListMemesParams{
+		Offset:        &offset,
+		Limit:         &limit,
+		SortBy:        (*apiclient.ListMemesParamsSortBy)(&sortBy),
+		BoardId:       boards,
+		MediaType:     (*apiclient.ListMemesParamsMediaType)(mediaType),
+		CreatedAfter:  after,
+		CreatedBefore: before,
+		Owner:         owner,
+	}
 	resp, err := c.api.ListMemesWithResponse(ctx, req, c.middlewares()...)
 	if err != nil {
 		err = fmt.Errorf("can't request: %w", err)
@@ -367,6 +509,38 @@ func (c Client) ListMemes(ctx context.Context, offset int, limit int, sortBy str
 	}
 }
 
+// ListBoardMemes implements ClientInterface.
+func (c Client) ListBoardMemes(ctx context.Context, boardID models.BoardID, offset, limit int) (memes []models.Meme, err error) {
+	req := &apiclient.ListBoardMemesParams{Offset: &offset, Limit: &limit}
+	resp, err := c.api.ListBoardMemesWithResponse(ctx, apiclient.BoardId(boardID), req, c.middlewares()...)
+	if err != nil {
+		err = fmt.Errorf("can't request: %w", err)
+		return
+	}
+	switch resp.StatusCode() {
+	case 200:
+		for _, m := range resp.JSON200.Items {
+			memes = append(memes, convertMemeToModel(m))
+		}
+		return
+	case 400:
+		err = parseApiError(*resp.JSON400)
+		return
+	case 401:
+		err = models.ErrUnauthorized
+		return
+	case 403:
+		err = models.ErrForbidden
+		return
+	case 404:
+		err = models.ErrBoardNotFound
+		return
+	default:
+		err = fmt.Errorf("unexpected response %d: %s", resp.StatusCode(), string(resp.Body))
+		return
+	}
+}
+
 // PostBoard implements ClientInterface.
 func (c Client) PostBoard(ctx context.Context, name string) (board models.Board, err error) {
 	req := apiclient.PostBoardJSONRequestBody{Name: name}
@@ -418,14 +592,47 @@ func (c Client) PostMeme(ctx context.Context, boardID models.BoardID, filename s
 	}
 }
 
+// PostMemeUsage implements ClientInterface.
+func (c Client) PostMemeUsage(ctx context.Context, memeID models.MemeID, source string) (err error) {
+	req := apiclient.PostMemeUsageJSONRequestBody{Source: apiclient.PostMemeUsageJSONBodySource(source)}
+	resp, err := c.api.PostMemeUsageWithResponse(ctx, apiclient.MemeId(memeID), req, c.middlewares()...)
+	if err != nil {
+		err = fmt.Errorf("can't request: %w", err)
+		return
+	}
+	switch resp.StatusCode() {
+	case 200:
+		return
+	case 400:
+		err = parseApiError(*resp.JSON400)
+		return
+	case 401:
+		err = models.ErrUnauthorized
+		return
+	case 403:
+		err = models.ErrForbidden
+		return
+	case 404:
+		err = models.ErrMemeNotFound
+		return
+	default:
+		err = fmt.Errorf("unexpected response %d: %s", resp.StatusCode(), string(resp.Body))
+		return
+	}
+}
+
 // PutMediaByID implements ClientInterface.
-func (c Client) PutMediaByID(ctx context.Context, media models.Media, filename string) (err error) {
+func (c Client) PutMediaByID(ctx context.Context, media models.Media, filename string, force bool) (err error) {
 	body, cType, err := createMultipart("media", filename, media.Body)
 	if err != nil {
 		err = fmt.Errorf("can't create multipart: %w", err)
 		return
 	}
-	resp, err := c.api.PutMediaByIDWithBodyWithResponse(ctx, apiclient.MediaId(media.ID), cType, body, c.middlewares()...)
+	req := &apiclient.PutMediaByIDParams{}
+	if force {
+		req.Force = &force
+	}
+	resp, err := c.api.PutMediaByIDWithBodyWithResponse(ctx, apiclient.MediaId(media.ID), req, cType, body, c.middlewares()...)
 	if err != nil {
 		err = fmt.Errorf("can't request: %w", err)
 		return
@@ -442,6 +649,9 @@ func (c Client) PutMediaByID(ctx context.Context, media models.Media, filename s
 	case 404:
 		err = models.ErrMediaNotFound
 		return
+	case 409:
+		err = parseDuplicateError(*resp.JSON409)
+		return
 	default:
 		err = fmt.Errorf("unexpected response %d: %s", resp.StatusCode(), string(resp.Body))
 		return
@@ -449,19 +659,185 @@ func (c Client) PutMediaByID(ctx context.Context, media models.Media, filename s
 }
 
 // SearchByBoardID implements ClientInterface.
-func (c Client) SearchMemes(ctx context.Context, offset int, limit int, general string) (memes []models.ScoredMeme, err error) {
-	req := &apiclient.SearchMemesParams{Offset: &offset, Limit: &limit, General: &general}
+func (c Client) SearchMemes(ctx context.Context, offset int, limit int, query models.SearchQuery) (page models.SearchPage, err error) {
+	boards, mediaType, after, before, owner := filterParams(query.Filter)
+	req := &apiclient.SearchMemesParams{
+		Offset:        &offset,
+		Limit:         &limit,
+		General:       &query.General,
+		Text:          optional(query.Text),
+		Tags:          optional(query.Tags),
+		Source:        optional(query.Source),
+		BoardId:       boards,
+		MediaType:     (*apiclient.SearchMemesParamsMediaType)(mediaType),
+		CreatedAfter:  after,
+		CreatedBefore: before,
+		Owner:         owner,
+	}
+	if query.Explain {
+		req.Explain = &query.Explain
+	}
+	req.Cursor = optional(query.Cursor)
 	resp, err := c.api.SearchMemesWithResponse(ctx, req, c.middlewares()...)
 	if err != nil {
 		err = fmt.Errorf("can't request: %w", err)
 		return
 	}
 	switch resp.StatusCode() {
+	case 200:
+		page = models.SearchPage{Page: resp.JSON200.Page, PageSize: resp.JSON200.PageSize, NextCursor: deref(resp.JSON200.NextCursor)}
+		for _, m := range resp.JSON200.Items {
+			page.Memes = append(page.Memes, convertScoredToModel(m))
+		}
+		return
+	case 400:
+		err = parseApiError(*resp.JSON400)
+		return
+	case 401:
+		err = models.ErrUnauthorized
+		return
+	case 403:
+		err = models.ErrForbidden
+		return
+	default:
+		err = fmt.Errorf("unexpected response %d: %s", resp.StatusCode(), string(resp.Body))
+		return
+	}
+}
+
+// SearchBoardMemes implements ClientInterface. Board and owner filters of
+// query are ignored.
+func (c Client) SearchBoardMemes(ctx context.Context, boardID models.BoardID, offset int, limit int, query models.SearchQuery) (page models.SearchPage, err error) {
+	_, mediaType, after, before, _ := filterParams(query.Filter)
+	req := &apiclient.SearchBoardMemesParams{
+		Offset:        &offset,
+		Limit:         &limit,
+		General:       &query.General,
+		Text:          optional(query.Text),
+		Tags:          optional(query.Tags),
+		Source:        optional(query.Source),
+		MediaType:     (*apiclient.SearchBoardMemesParamsMediaType)(mediaType),
+		CreatedAfter:  after,
+		CreatedBefore: before,
+	}
+	if query.Explain {
+		req.Explain = &query.Explain
+	}
+	req.Cursor = optional(query.Cursor)
+	resp, err := c.api.SearchBoardMemesWithResponse(ctx, apiclient.BoardId(boardID), req, c.middlewares()...)
+	if err != nil {
+		err = fmt.Errorf("can't request: %w", err)
+		return
+	}
+	switch resp.StatusCode() {
+	case 200:
+		page = models.SearchPage{Page: resp.JSON200.Page, PageSize: resp.JSON200.PageSize, NextCursor: deref(resp.JSON200.NextCursor)}
+		for _, m := range resp.JSON200.Items {
+			page.Memes = append(page.Memes, convertScoredToModel(m))
+		}
+		return
+	case 400:
+		err = parseApiError(*resp.JSON400)
+		return
+	case 401:
+		err = models.ErrUnauthorized
+		return
+	case 403:
+		err = models.ErrForbidden
+		return
+	case 404:
+		err = models.ErrBoardNotFound
+		return
+	default:
+		err = fmt.Errorf("unexpected response %d: %s", resp.StatusCode(), string(resp.Body))
+		return
+	}
+}
+
+// SuggestQueries implements ClientInterface.
+func (c Client) SuggestQueries(ctx context.Context, prefix string, limit int) (sug models.Suggestions, err error) {
+	req := &apiclient.SuggestQueriesParams{Prefix: prefix, Limit: &limit}
+	resp, err := c.api.SuggestQueriesWithResponse(ctx, req, c.middlewares()...)
+	if err != nil {
+		err = fmt.Errorf("can't request: %w", err)
+		return
+	}
+	switch resp.StatusCode() {
+	case 200:
+		sug = models.Suggestions{Completions: resp.JSON200.Completions, DidYouMean: deref(resp.JSON200.DidYouMean)}
+		return
+	case 400:
+		err = parseApiError(*resp.JSON400)
+		return
+	case 401:
+		err = models.ErrUnauthorized
+		return
+	case 403:
+		err = models.ErrForbidden
+		return
+	default:
+		err = fmt.Errorf("unexpected response %d: %s", resp.StatusCode(), string(resp.Body))
+		return
+	}
+}
+
+// SuggestDescription implements ClientInterface.
+func (c Client) SuggestDescription(ctx context.Context, memeID models.MemeID, limit int) (sugs []models.DescriptionSuggestion, err error) {
+	req := &apiclient.SuggestDescriptionParams{Limit: &limit}
+	resp, err := c.api.SuggestDescriptionWithResponse(ctx, apiclient.MemeId(memeID), req, c.middlewares()...)
+	if err != nil {
+		err = fmt.Errorf("can't request: %w", err)
+		return
+	}
+	switch resp.StatusCode() {
+	case 200:
+		for _, s := range resp.JSON200.Items {
+			sugs = append(sugs, models.DescriptionSuggestion{Word: s.Word, Source: string(s.Source)})
+		}
+		return
+	case 400:
+		err = parseApiError(*resp.JSON400)
+		return
+	case 401:
+		err = models.ErrUnauthorized
+		return
+	case 403:
+		err = models.ErrForbidden
+		return
+	case 404:
+		err = models.ErrMemeNotFound
+		return
+	default:
+		err = fmt.Errorf("unexpected response %d: %s", resp.StatusCode(), string(resp.Body))
+		return
+	}
+}
+
+// SearchMemesByImage implements ClientInterface.
+func (c Client) SearchMemesByImage(ctx context.Context, offset int, limit int, image []byte) (memes []models.ScoredMeme, err error) {
+	body, cType, err := createMultipart("media", "image", image)
+	if err != nil {
+		err = fmt.Errorf("can't create multipart: %w", err)
+		return
+	}
+	req := &apiclient.SearchMemesByImageParams{
+		Offset: &offset,
+		Limit:  &limit,
+	}
+	resp, err := c.api.SearchMemesByImageWithBodyWithResponse(ctx, req, cType, body, c.middlewares()...)
+	if err != nil {
+		err = fmt.Errorf("can't request: %w", err)
+		return
+	}
+	switch resp.StatusCode() {
 	case 200:
 		for _, m := range resp.JSON200.Items {
 			memes = append(memes, convertScoredToModel(m))
 		}
 		return
+	case 400:
+		err = parseApiError(*resp.JSON400)
+		return
 	case 401:
 		err = models.ErrUnauthorized
 		return
@@ -524,6 +900,32 @@ func (c Client) UnsubscribeByBoardID(ctx context.Context, boardID models.BoardID
 	}
 }
 
+// SetSubscriptionWeight implements ClientInterface.
+func (c Client) SetSubscriptionWeight(ctx context.Context, boardID models.BoardID, weight float64) (err error) {
+	req := apiclient.SetSubscriptionWeightJSONRequestBody{Weight: weight}
+	resp, err := c.api.SetSubscriptionWeightWithResponse(ctx, apiclient.BoardId(boardID), req, c.middlewares()...)
+	if err != nil {
+		err = fmt.Errorf("can't request: %w", err)
+		return
+	}
+	switch resp.StatusCode() {
+	case 200:
+		return
+	case 400:
+		err = parseApiError(*resp.JSON400)
+		return
+	case 401:
+		err = models.ErrUnauthorized
+		return
+	case 404:
+		err = models.ErrSubNotFound
+		return
+	default:
+		err = fmt.Errorf("unexpected response %d: %s", resp.StatusCode(), string(resp.Body))
+		return
+	}
+}
+
 // UpdateBoardByID implements ClientInterface.
 func (c Client) UpdateBoardByID(ctx context.Context, boardID models.BoardID, name *string, owner *models.UserID) (board models.Board, err error) {
 	req := apiclient.UpdateBoardByIDJSONRequestBody{Name: name, Owner: (*string)(owner)}
@@ -10,6 +10,7 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"time"
 )
 
 func parseApiError(e apiclient.Error) error {
@@ -21,6 +22,15 @@ func parseApiError(e apiclient.Error) error {
 	return fmt.Errorf("unexpected error: %s, %s (%v)", e.Id, e.Message, e.Body) //TODO
 }
 
+func parseDuplicateError(e apiclient.Error) error {
+	if e.Id != "DUPLICATE_MEDIA" || e.Body == nil {
+		return parseApiError(e)
+	}
+	id, _ := (*e.Body)["memeId"].(string)
+	exact, _ := (*e.Body)["exact"].(bool)
+	return models.ErrDuplicateMedia{Meme: models.MemeID(id), Exact: exact}
+}
+
 func convertMemeToModel(m apiclient.Meme) models.Meme {
 	dsc := map[string]string{}
 	for k, v := range m.Description {
@@ -40,18 +50,60 @@ func convertUserToModel(u apiclient.User) models.User {
 	return models.User{ID: models.UserID(u.Id), Login: u.Login}
 }
 func convertBoardToModel(b apiclient.Board) models.Board {
-	return models.Board{
+	board := models.Board{
 		ID:    models.BoardID(b.Id),
 		Owner: models.UserID(b.Owner),
 		Name:  b.Name,
 	}
+	if b.Settings != nil {
+		board.Settings.Synonyms = deref(b.Settings.Synonyms)
+	}
+	return board
 }
 
 func convertScoredToModel(m apiclient.ScoredMeme) models.ScoredMeme {
-	return models.ScoredMeme{
+	res := models.ScoredMeme{
 		Score: m.Score,
 		Meme:  convertMemeToModel(m.Meme),
 	}
+	if m.Corrections != nil {
+		res.Corrections = *m.Corrections
+	}
+	if m.Explain != nil {
+		for _, tm := range *m.Explain {
+			res.Explain = append(res.Explain, convertTermMatchToModel(tm))
+		}
+	}
+	return res
+}
+
+func convertQueryStatsToModel(stats apiclient.QueryStats) []models.QueryStat {
+	res := make([]models.QueryStat, 0, len(stats.Items))
+	for _, st := range stats.Items {
+		res = append(res, models.QueryStat{
+			Query:          st.Query,
+			Searches:       st.Searches,
+			Users:          st.Users,
+			AvgResults:     st.AvgResults,
+			LastSearchedAt: st.LastSearchedAt,
+		})
+	}
+	return res
+}
+
+func convertTermMatchToModel(tm apiclient.TermMatch) models.TermMatch {
+	return models.TermMatch{
+		Term:       tm.Term,
+		Variant:    deref(tm.Variant),
+		Field:      deref(tm.Field),
+		Word:       deref(tm.Word),
+		Similarity: deref(tm.Similarity),
+		Threshold:  deref(tm.Threshold),
+		Weight:     deref(tm.Weight),
+		IDF:        deref(tm.Idf),
+		Score:      tm.Score,
+		Used:       tm.Used,
+	}
 }
 
 func convertMapToAny(o map[string]string) map[string]any {
@@ -126,3 +178,37 @@ func (c *Client) GetToken() string {
 func ptr[T any](x T) *T {
 	return &x
 }
+
+// optional returns nil for empty strings so they aren't sent as parameters.
+// filterParams converts filter to query parameters of ListMemes and SearchMemes.
+func filterParams(f models.MemeFilter) (boards *[]string, mediaType *string, after, before *time.Time, owner *string) {
+	if len(f.Boards) > 0 {
+		ids := make([]string, 0, len(f.Boards))
+		for _, b := range f.Boards {
+			ids = append(ids, string(b))
+		}
+		boards = &ids
+	}
+	if !f.CreatedAfter.IsZero() {
+		after = &f.CreatedAfter
+	}
+	if !f.CreatedBefore.IsZero() {
+		before = &f.CreatedBefore
+	}
+	return boards, optional(f.MediaType), after, before, optional(string(f.Owner))
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
@@ -3,7 +3,14 @@ package models
 type BoardID string
 
 type Board struct {
-	ID    BoardID `json:"id"    db:"id"`
-	Owner UserID  `json:"owner" db:"owner_id"`
-	Name  string  `json:"name"  db:"name"`
+	ID       BoardID       `json:"id"       db:"id"`
+	Owner    UserID        `json:"owner"    db:"owner_id"`
+	Name     string        `json:"name"     db:"name"`
+	Settings BoardSettings `json:"settings"`
+}
+
+// BoardSettings customize search on a board.
+type BoardSettings struct {
+	// Synonyms are groups of words and phrases which match each other.
+	Synonyms [][]string `json:"synonyms,omitempty"`
 }
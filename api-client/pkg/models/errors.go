@@ -35,3 +35,16 @@ func (e ErrInvalidInput) Is(taget error) bool {
 		(t.Param == "" || t.Param == e.Param)
 
 }
+
+// ErrDuplicateMedia is returned when the board already has the same
+// or a near-identical media.
+type ErrDuplicateMedia struct {
+	// Meme is the meme which has the media.
+	Meme MemeID
+	// Exact is set when the media content is the same.
+	Exact bool
+}
+
+func (e ErrDuplicateMedia) Error() string {
+	return fmt.Sprintf("media is already used by meme %s", e.Meme)
+}
@@ -18,4 +18,100 @@ type Meme struct {
 type ScoredMeme struct {
 	Score float64 `json:"score"`
 	Meme  Meme    `json:"meme"`
+	// Corrections maps query terms which matched only after keyboard layout
+	// or transliteration correction to the corrected text.
+	Corrections map[string]string `json:"corrections,omitempty"`
+	// Explain holds matches of every query term when SearchQuery.Explain is set.
+	Explain []TermMatch `json:"explain,omitempty"`
+}
+
+// TermMatch explains how a query term contributed to a meme score.
+type TermMatch struct {
+	Term       string  `json:"term"`
+	Variant    string  `json:"variant,omitempty"`
+	Field      string  `json:"field,omitempty"`
+	Word       string  `json:"word,omitempty"`
+	Similarity float64 `json:"similarity"`
+	Threshold  float64 `json:"threshold"`
+	Weight     float64 `json:"weight"`
+	IDF        float64 `json:"idf,omitempty"`
+	Score      float64 `json:"score"`
+	Used       bool    `json:"used"`
+}
+
+// SearchQuery holds searched text per description field.
+// General is matched against every field, the others only against their own field.
+type SearchQuery struct {
+	General string
+	Text    string
+	Tags    string
+	Source  string
+	// Explain asks the server how every query term matched the results.
+	Explain bool
+	// Filter is applied before ranking.
+	Filter MemeFilter
+	// Cursor is NextCursor of the previous page. It continues the same
+	// results, the other fields and offset are ignored then.
+	Cursor string
+}
+
+// SearchPage is a page of search results.
+type SearchPage struct {
+	Memes []ScoredMeme
+	// Page is the number of the page starting from 0.
+	Page     int
+	PageSize int
+	// NextCursor continues the same results, it is empty on the last page.
+	NextCursor string
+}
+
+const (
+	MediaTypePhoto = "photo"
+	MediaTypeVideo = "video"
+)
+
+// MemeFilter narrows listed and searched memes. Zero fields don't filter.
+type MemeFilter struct {
+	// Boards keeps memes of any of the boards.
+	Boards []BoardID
+	// MediaType keeps memes of the media type.
+	MediaType string
+	// CreatedAfter keeps memes created after the moment.
+	CreatedAfter time.Time
+	// CreatedBefore keeps memes created before the moment.
+	CreatedBefore time.Time
+	// Owner keeps memes of boards owned by the user.
+	Owner UserID
+}
+
+const (
+	UsageSourceInline = "inline"
+	UsageSourceSearch = "search"
+)
+
+// Suggestions are search queries suggested for a typed prefix.
+type Suggestions struct {
+	// Completions are prefix with its last word completed.
+	Completions []string
+	// DidYouMean is prefix with misspelled words corrected. It is set
+	// only when no word of prefix is found on visible boards.
+	DidYouMean string
+}
+
+// QueryStat aggregates searches of a query over a board.
+type QueryStat struct {
+	Query    string
+	Searches int
+	Users    int
+	// AvgResults is the average number of results.
+	AvgResults     float64
+	LastSearchedAt time.Time
+}
+
+// DescriptionSuggestion is a word suggested for a meme description.
+type DescriptionSuggestion struct {
+	Word string
+	// Source is "similar" for words of memes with similar media and
+	// "board" for frequent words of the meme board.
+	Source string
 }
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"expvar"
 	"fmt"
 	"log/slog"
 	"memesearch/internal/api"
@@ -8,7 +10,9 @@ import (
 	"memesearch/internal/apiserver/middleware"
 	"memesearch/internal/config"
 	"memesearch/internal/contextlogger"
+	"memesearch/internal/searchindex"
 	"memesearch/internal/searchranker"
+	"memesearch/internal/searchsetup"
 	"memesearch/internal/storage"
 	"net/http"
 	"os"
@@ -20,14 +24,42 @@ func main() {
 	cfg := getConfig()
 	s, err := storage.New(cfg)
 	processError("Failed to create storage", err)
-	ranker := &searchranker.DefaultRanker{}
-	api := api.New(s, cfg.Secrets, ranker)
+	analyzer, err := searchsetup.NewAnalyzer(cfg.Search)
+	processError("Failed to create analyzer", err)
+	ranker, index, err := getRanker(cfg, &s, analyzer)
+	processError("Failed to create ranker", err)
+	api := api.New(s, cfg.Secrets, ranker, index, analyzer, cfg.Search)
 	server := apiserver.NewHandler(api, []middleware.Middleware{middleware.Logger(), middleware.Auth(api)})
+	go backfillMediaHashes(api)
+	expvar.Publish("searchCache", expvar.Func(func() any { return api.SearchCacheStats() }))
+	expvar.Publish("searchLog", expvar.Func(func() any { return api.SearchLogStats() }))
+	go serveAdmin(cfg.Server.AdminPort)
 	slog.Info("Run server", "port", cfg.Server.Port)
 	err = http.ListenAndServe(fmt.Sprintf("0.0.0.0:%d", cfg.Server.Port), server)
 	slog.Info("Server stopped", "err", err)
 }
 
+// serveAdmin serves /debug/vars on localhost, so that cache and search log
+// stats aren't exposed to clients.
+func serveAdmin(port int) {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	slog.Info("Run admin server", "port", port)
+	err := http.ListenAndServe(fmt.Sprintf("127.0.0.1:%d", port), mux)
+	slog.Error("Admin server stopped", "err", err)
+}
+
+// backfillMediaHashes hashes media uploaded before duplicate detection,
+// so that they are found as duplicates and by image search.
+func backfillMediaHashes(a *api.API) {
+	n, err := a.BackfillMediaHashes(context.Background())
+	if err != nil {
+		slog.Error("Can't backfill media hashes", "hashed", n, "err", err)
+		return
+	}
+	slog.Info("Media hashes backfilled", "hashed", n)
+}
+
 func setLogger() {
 	logFolder := os.Getenv("LOG_FOLDER")
 	date := time.Now().Format("2006-01-02")
@@ -48,6 +80,56 @@ func getConfig() config.Config {
 	return cfg
 }
 
+// getRanker creates the ranker chosen in config or the experiment comparing
+// rankers. When any of them ranks in memory a search index is built as well
+// and kept in sync through s.MemeRepo.
+func getRanker(cfg config.Config, s *storage.Storage, analyzer searchranker.Analyzer) (searchranker.Ranker, *searchindex.Index, error) {
+	registry := searchsetup.NewRegistry(cfg, s, analyzer)
+	exp := cfg.Search.Experiment
+	names := []string{cfg.Search.Ranker}
+	if exp.Mode != "" {
+		names = exp.Rankers
+	}
+
+	arms := make([]searchranker.Arm, 0, len(names))
+	inMemory := false
+	for _, name := range names {
+		ranker, err := registry.New(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := ranker.(searchranker.Searcher); !ok {
+			inMemory = true
+		}
+		if p := cfg.Search.Popularity; p.Enabled {
+			ranker = searchranker.WithPopularity(ranker, s.UsageRepo, searchranker.PopularityWeights{Global: p.GlobalWeight, User: p.UserWeight})
+		}
+		if b := cfg.Search.Boost; b.Enabled {
+			ranker = searchranker.WithBoosts(ranker, s.SubsciptionRepo, searchranker.BoostWeights{Own: b.OwnWeight, Recency: b.RecencyWeight, HalfLife: b.RecencyHalfLife})
+		}
+		arms = append(arms, searchranker.Arm{Name: name, Ranker: ranker})
+	}
+
+	var index *searchindex.Index
+	if inMemory {
+		index = searchindex.New(analyzer)
+		err := index.Build(context.Background(), s.MemeRepo)
+		if err != nil {
+			return nil, nil, fmt.Errorf("can't build search index: %w", err)
+		}
+		s.MemeRepo = searchindex.NewMemeRepo(s.MemeRepo, index)
+	}
+
+	if exp.Mode == "" {
+		return arms[0].Ranker, index, nil
+	}
+	experiment, err := searchranker.NewExperiment(exp.Name, exp.Mode, arms)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't create experiment: %w", err)
+	}
+	return experiment, index, nil
+}
+
 func processError(msg string, err error) {
 	if err != nil {
 		slog.Error(msg, slog.String("error", err.Error()))
@@ -0,0 +1,150 @@
+// Command searcheval measures quality of registered rankers on a labeled
+// dataset and reports NDCG@k, MRR, recall@k and latency of every ranker.
+//
+// The dataset has a JSON object per line:
+//
+//	{"query": "grumpy cat", "expected": [{"memeId": "42", "relevance": 3}]}
+//
+// Memes are ranked over a snapshot exported from the database with -export
+// or loaded from the database when -snapshot isn't given.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"memesearch/internal/config"
+	"memesearch/internal/models"
+	"memesearch/internal/searcheval"
+	"memesearch/internal/searchranker"
+	"memesearch/internal/searchsetup"
+	"memesearch/internal/storage"
+	"memesearch/internal/storage/psql"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+func main() {
+	configPath := flag.String("config", os.Getenv("MS_API_CONFIG_PATH"), "API server config")
+	datasetPath := flag.String("dataset", "", "labeled queries in JSONL")
+	snapshotPath := flag.String("snapshot", "", "memes snapshot in JSONL, memes are loaded from the database when empty")
+	exportPath := flag.String("export", "", "export memes from the database to the snapshot file and exit")
+	rankers := flag.String("rankers", "", "comma separated rankers to evaluate, all registered when empty")
+	k := flag.Int("k", 10, "number of top results NDCG and recall are measured on")
+	minNDCG := flag.Float64("min-ndcg", 0, "exit with error when NDCG of any ranker is lower")
+	flag.Parse()
+
+	if *configPath == "" {
+		slog.Error("Config path is not specified")
+		os.Exit(1)
+	}
+	cfg, err := config.LoadConfig(*configPath)
+	processError("Can't load config", err)
+	ctx := context.Background()
+
+	if *exportPath != "" {
+		memes, err := loadMemes(ctx, cfg)
+		processError("Can't load memes", err)
+		f, err := os.Create(*exportPath)
+		processError("Can't create snapshot", err)
+		processError("Can't write snapshot", searcheval.WriteSnapshot(f, memes))
+		processError("Can't close snapshot", f.Close())
+		slog.Info("Snapshot exported", "memes", len(memes), "path", *exportPath)
+		return
+	}
+
+	if *datasetPath == "" {
+		slog.Error("Dataset path is not specified")
+		os.Exit(1)
+	}
+	cases, err := readFile(*datasetPath, searcheval.ReadDataset)
+	processError("Can't read dataset", err)
+
+	var memes []models.Meme
+	if *snapshotPath != "" {
+		memes, err = readFile(*snapshotPath, searcheval.ReadSnapshot)
+	} else {
+		memes, err = loadMemes(ctx, cfg)
+	}
+	processError("Can't load memes", err)
+
+	analyzer, err := searchsetup.NewAnalyzer(cfg.Search)
+	processError("Can't create analyzer", err)
+	// rankers with own indexes build them over the snapshot without persisting
+	cfg.Search.Semantic.Path = ""
+	s := storage.Storage{MemeRepo: searcheval.NewSnapshotRepo(memes)}
+	registry := searchsetup.NewRegistry(cfg, &s, analyzer)
+	names := registry.Names()
+	if *rankers != "" {
+		names = strings.Split(*rankers, ",")
+	}
+
+	evaluator := searcheval.NewEvaluator(analyzer, cfg.Search.FieldWeights, memes, *k)
+	reports := make([]searcheval.Report, 0, len(names))
+	for _, name := range names {
+		ranker, err := registry.New(name)
+		if err != nil {
+			slog.Warn("Ranker skipped", "ranker", name, "err", err)
+			continue
+		}
+		if _, ok := ranker.(searchranker.Searcher); ok {
+			slog.Warn("Ranker skipped, it searches the database instead of the snapshot", "ranker", name)
+			continue
+		}
+		report, err := evaluator.Run(ctx, name, ranker, cases)
+		processError("Can't evaluate "+name, err)
+		reports = append(reports, report)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ranker\tqueries\tNDCG@%d\tMRR\trecall@%d\tlatency\tp95\n", *k, *k)
+	for _, r := range reports {
+		fmt.Fprintf(w, "%s\t%d\t%.4f\t%.4f\t%.4f\t%s\t%s\n", r.Ranker, r.Queries, r.NDCG, r.MRR, r.Recall, r.Latency, r.LatencyP95)
+	}
+	w.Flush()
+
+	if slices.ContainsFunc(reports, func(r searcheval.Report) bool { return r.NDCG < *minNDCG }) {
+		slog.Error("NDCG is lower than minimum", "min", *minNDCG)
+		os.Exit(1)
+	}
+}
+
+// loadMemes exports every meme from the database.
+func loadMemes(ctx context.Context, cfg config.Config) ([]models.Meme, error) {
+	repo, err := psql.NewMemeStore(cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("can't load meme store: %w", err)
+	}
+	const batchSize = 200
+	res := []models.Meme{}
+	for offset := 0; ; offset += batchSize {
+		memes, err := repo.ListAllMemes(ctx, offset, batchSize)
+		if err != nil {
+			return nil, fmt.Errorf("can't list memes with offset %d: %w", offset, err)
+		}
+		if len(memes) == 0 {
+			return res, nil
+		}
+		res = append(res, memes...)
+	}
+}
+
+func readFile[T any](path string, read func(io.Reader) ([]T, error)) ([]T, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return read(f)
+}
+
+func processError(msg string, err error) {
+	if err != nil {
+		slog.Error(msg, slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+}
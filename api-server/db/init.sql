@@ -17,6 +17,8 @@ CREATE TABLE IF NOT EXISTS boards
     name TEXT
 );
 
+ALTER TABLE boards ADD COLUMN IF NOT EXISTS settings TEXT NOT NULL DEFAULT '{}';
+
 CREATE TABLE IF NOT EXISTS users
 (
     id VARCHAR(63) PRIMARY KEY,
@@ -30,6 +32,29 @@ CREATE TABLE IF NOT EXISTS medias
     body BYTEA
 );
 
+CREATE TABLE IF NOT EXISTS media_hashes
+(
+    id VARCHAR(63) PRIMARY KEY,
+    sha256 TEXT,
+    dhash BIGINT,
+    phash BIGINT
+);
+
+ALTER TABLE media_hashes ADD COLUMN IF NOT EXISTS board_id VARCHAR(63);
+ALTER TABLE media_hashes ADD COLUMN IF NOT EXISTS forced BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE media_hashes h SET board_id = memes.board_id
+FROM memes WHERE memes.id = h.id AND h.board_id IS NULL;
+
+-- duplicates stored before the index existed are kept as forced
+UPDATE media_hashes h SET forced = TRUE
+WHERE NOT h.forced AND EXISTS (
+    SELECT 1 FROM media_hashes o
+    WHERE o.board_id = h.board_id AND o.sha256 = h.sha256 AND NOT o.forced AND o.id < h.id
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS media_hashes_board_sha256_idx ON media_hashes (board_id, sha256) WHERE NOT forced;
+
 CREATE TABLE IF NOT EXISTS subscriptions
 (
     user_id VARCHAR(63),
@@ -38,4 +63,41 @@ CREATE TABLE IF NOT EXISTS subscriptions
     PRIMARY KEY (user_id, board_id)
 );
 
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS weight DOUBLE PRECISION NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS usage_events
+(
+    meme_id VARCHAR(63),
+    user_id VARCHAR(63),
+    source TEXT,
+    created_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS usage_events_meme_id_idx ON usage_events (meme_id);
+
+CREATE TABLE IF NOT EXISTS search_log
+(
+    user_id VARCHAR(63),
+    query TEXT,
+    filter TEXT,
+    boards TEXT[],
+    results INT,
+    top_ids TEXT[],
+    top_score DOUBLE PRECISION,
+    latency_ms DOUBLE PRECISION,
+    created_at TIMESTAMP
+);
+
+ALTER TABLE search_log ADD COLUMN IF NOT EXISTS ranker TEXT;
+
+CREATE INDEX IF NOT EXISTS search_log_boards_idx ON search_log USING GIN (boards);
+
+ALTER TABLE memes ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        jsonb_to_tsvector('russian', descriptions::jsonb, '["string"]') ||
+        jsonb_to_tsvector('english', descriptions::jsonb, '["string"]')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS memes_search_vector_idx ON memes USING GIN (search_vector);
+
 COMMIT;
\ No newline at end of file
@@ -2,20 +2,52 @@ package api
 
 import (
 	"memesearch/internal/config"
+	"memesearch/internal/lru"
+	"memesearch/internal/searchindex"
 	"memesearch/internal/searchranker"
 	"memesearch/internal/storage"
 )
 
 type api struct {
-	storage storage.Storage
-	secrets config.SecretConfig
-	ranker  searchranker.Ranker
+	storage  storage.Storage
+	secrets  config.SecretConfig
+	ranker   searchranker.Ranker
+	index    *searchindex.Index
+	analyzer searchranker.Analyzer
+	fields   searchranker.FieldWeights
+	cursors  *lru.Cache[string, *searchSnapshot]
+	cache    *searchCache
+	logCfg   config.SearchLogConfig
+	// searchLog is nil unless logging is enabled.
+	searchLog *searchLogger
+	// rankerName names the ranker of logged searches. It is empty during
+	// experiments whose results carry names of their arms instead.
+	rankerName string
+	// popularity weighs usage of memes in suggestions, it is zero unless
+	// popularity is enabled.
+	popularity searchranker.PopularityWeights
 }
 
-func newApi(s storage.Storage, secrets config.SecretConfig, ranker searchranker.Ranker) *api {
-	return &api{
-		storage: s,
-		secrets: secrets,
-		ranker:  ranker,
+func newApi(s storage.Storage, secrets config.SecretConfig, ranker searchranker.Ranker, index *searchindex.Index, analyzer searchranker.Analyzer, cfg config.SearchConfig) *api {
+	a := &api{
+		storage:  s,
+		secrets:  secrets,
+		ranker:   ranker,
+		index:    index,
+		analyzer: analyzer,
+		fields:   cfg.FieldWeights,
+		cursors:  lru.New[string, *searchSnapshot](cfg.Cursor.Size, cfg.Cursor.TTL),
+		cache:    newSearchCache(cfg.Cache),
+		logCfg:   cfg.Log,
 	}
+	if cfg.Experiment.Mode == "" {
+		a.rankerName = cfg.Ranker
+	}
+	if p := cfg.Popularity; p.Enabled {
+		a.popularity = searchranker.PopularityWeights{Global: p.GlobalWeight, User: p.UserWeight}
+	}
+	if cfg.Log.Enabled {
+		a.searchLog = newSearchLogger(s.SearchLogRepo, cfg.Log.QueueSize)
+	}
+	return a
 }
@@ -12,6 +12,8 @@ func (a *api) CreateBoard(ctx context.Context, name string) (models.Board, error
 	if err != nil {
 		return models.Board{}, fmt.Errorf("can't create board: %w", err)
 	}
+	// the new board becomes visible to its owner
+	a.cache.invalidateUser(userID)
 
 	return board, nil
 }
@@ -45,6 +47,11 @@ func (a *api) UpdateBoard(ctx context.Context, id models.BoardID, name *string,
 	if err != nil {
 		return models.Board{}, fmt.Errorf("can't update board: %w", err)
 	}
+	// the owner sees memes of own boards
+	a.cache.invalidateBoards(id)
+	if owner != nil {
+		a.cache.invalidateUser(*owner)
+	}
 
 	board, err = a.storage.GetBoardByID(ctx, id)
 	if err != nil {
@@ -63,6 +70,7 @@ func (a *api) DeleteBoard(ctx context.Context, id models.BoardID) (models.Board,
 	if err != nil {
 		return models.Board{}, fmt.Errorf("can't delete board: %w", err)
 	}
+	a.cache.invalidateBoards(id)
 	return board, nil
 }
 
@@ -74,3 +82,61 @@ func (a *api) ListBoards(ctx context.Context, offset, limit int, sortBy string)
 	}
 	return boards, nil
 }
+
+// visibleBoards returns IDs of boards the user owns or is subscribed to
+// which pass board filters of filter.
+func (a *api) visibleBoards(ctx context.Context, userID models.UserID, filter models.MemeFilter) ([]models.BoardID, error) {
+	boards, err := a.listVisibleBoards(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]models.BoardID, 0, len(boards))
+	for _, b := range boards {
+		ids = append(ids, b.ID)
+	}
+	return ids, nil
+}
+
+// listVisibleBoards is visibleBoards returning whole boards.
+func (a *api) listVisibleBoards(ctx context.Context, userID models.UserID, filter models.MemeFilter) ([]models.Board, error) {
+	const batchSize = 100
+	res := []models.Board{}
+	for offset := 0; ; offset += batchSize {
+		boards, err := a.storage.ListBoards(ctx, userID, offset, batchSize, "id")
+		if err != nil {
+			return nil, fmt.Errorf("can't list boards with offset %d: %w", offset, err)
+		}
+		for _, b := range boards {
+			if filter.MatchBoard(b) {
+				res = append(res, b)
+			}
+		}
+		if len(boards) < batchSize {
+			break
+		}
+	}
+	return res, nil
+}
+
+func (a *api) GetBoardSynonyms(ctx context.Context, id models.BoardID) ([][]string, error) {
+	board, err := a.GetBoardByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("can't get board: %w", err)
+	}
+	return board.Settings.Synonyms, nil
+}
+
+func (a *api) SetBoardSynonyms(ctx context.Context, id models.BoardID, groups [][]string) ([][]string, error) {
+	board, err := a.GetBoardByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("can't get board: %w", err)
+	}
+
+	board.Settings.Synonyms = groups
+	err = a.storage.UpdateBoard(ctx, board)
+	if err != nil {
+		return nil, fmt.Errorf("can't update board: %w", err)
+	}
+	a.cache.invalidateBoards(id)
+	return groups, nil
+}
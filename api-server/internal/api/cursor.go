@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+	"strconv"
+	"strings"
+)
+
+// searchSnapshot keeps results of a search, so that its next pages are
+// served by cursor without ranking again. Pages don't shift when memes are
+// added or changed meanwhile.
+type searchSnapshot struct {
+	userID models.UserID
+	// results are the ranked memes.
+	results []searchranker.ScroredMeme
+	// boards are the boards the results were ranked over.
+	boards []models.BoardID
+	// board is the searched board, it is empty when every visible board
+	// was searched.
+	board models.BoardID
+	// list is set instead of results for searches without searched words.
+	// They list memes in ID order which keeps earlier pages in place.
+	list func(ctx context.Context, offset, limit int) ([]searchranker.ScroredMeme, error)
+}
+
+// page returns limit results starting from offset and whether more follow.
+func (s *searchSnapshot) page(ctx context.Context, offset, limit int) ([]searchranker.ScroredMeme, bool, error) {
+	if s.list != nil {
+		memes, err := s.list(ctx, offset, limit+1)
+		if err != nil {
+			return nil, false, err
+		}
+		return memes[:min(limit, len(memes))], len(memes) > limit, nil
+	}
+
+	begin := min(offset, len(s.results))
+	end := min(offset+limit, len(s.results))
+	return s.results[begin:end], end < len(s.results), nil
+}
+
+// encodeCursor makes an opaque cursor pointing to offset of the snapshot id
+// of a search over board, which is empty for searches over visible boards.
+func encodeCursor(id string, board models.BoardID, offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id + "." + strconv.Itoa(offset) + "." + string(board)))
+}
+
+func decodeCursor(cursor string) (id string, board models.BoardID, offset int, ok bool) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", "", 0, false
+	}
+	id, rest, ok := strings.Cut(string(b), ".")
+	if !ok {
+		return "", "", 0, false
+	}
+	off, brd, ok := strings.Cut(rest, ".")
+	if !ok {
+		return "", "", 0, false
+	}
+	offset, err = strconv.Atoi(off)
+	if err != nil || offset < 0 {
+		return "", "", 0, false
+	}
+	return id, models.BoardID(brd), offset, true
+}
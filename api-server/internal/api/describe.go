@@ -0,0 +1,164 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+	"slices"
+)
+
+const (
+	// SuggestionSourceSimilar marks words of memes with similar media.
+	SuggestionSourceSimilar = "similar"
+	// SuggestionSourceBoard marks frequent words of the meme board.
+	SuggestionSourceBoard = "board"
+)
+
+// DescriptionSuggestion is a word suggested for a meme description.
+type DescriptionSuggestion struct {
+	Word   string
+	Source string
+}
+
+const (
+	// similarMediaDistance is the largest hash distance of media whose
+	// meme words are suggested.
+	similarMediaDistance = 10
+	// maxSimilarMemes bounds the number of similar memes words are taken from.
+	maxSimilarMemes = 20
+)
+
+// SuggestDescription suggests up to limit words missing in the description
+// of the meme. Words of visible memes with similar media come first, weighed
+// by similarity, then frequent words of the meme board.
+func (a *api) SuggestDescription(ctx context.Context, id models.MemeID, limit int) ([]DescriptionSuggestion, error) {
+	logger := slog.Default().With("from", "api.SuggestDescription")
+	logger.InfoContext(ctx, "Started")
+
+	meme, err := a.GetMemeByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("can't get meme: %w", err)
+	}
+	known := map[string]struct{}{}
+	for _, t := range searchranker.Tokens(meme, a.analyzer) {
+		known[t] = struct{}{}
+	}
+	// missing reports whether word is worth suggesting and remembers it
+	missing := func(word string) bool {
+		terms := a.analyzer.Analyze(word)
+		if len(terms) == 0 {
+			return false
+		}
+		for _, t := range terms {
+			if _, ok := known[t]; ok {
+				return false
+			}
+		}
+		for _, t := range terms {
+			known[t] = struct{}{}
+		}
+		return true
+	}
+
+	words, err := a.similarMediaWords(ctx, meme)
+	if err != nil {
+		return nil, err
+	}
+	res := []DescriptionSuggestion{}
+	for _, w := range words {
+		if len(res) == limit {
+			return res, nil
+		}
+		if missing(w) {
+			res = append(res, DescriptionSuggestion{Word: w, Source: SuggestionSourceSimilar})
+		}
+	}
+
+	if a.index == nil {
+		// rankers searching the database keep no vocabulary
+		return res, nil
+	}
+	for _, c := range a.index.Complete([]models.BoardID{meme.BoardID}, "", limit+len(known)) {
+		if len(res) == limit {
+			break
+		}
+		if missing(c.Word) {
+			res = append(res, DescriptionSuggestion{Word: c.Word, Source: SuggestionSourceBoard})
+		}
+	}
+	return res, nil
+}
+
+// similarMediaWords returns description words of visible memes whose media
+// looks like media of meme, most weighed first.
+func (a *api) similarMediaWords(ctx context.Context, meme models.Meme) ([]string, error) {
+	userID := GetUserID(ctx)
+	if userID == "" {
+		userID = "guest"
+	}
+	boards, err := a.visibleBoards(ctx, userID, models.MemeFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("can't get visible boards: %w", err)
+	}
+	if !slices.Contains(boards, meme.BoardID) {
+		boards = append(boards, meme.BoardID)
+	}
+	mhs, err := a.storage.ListMediaHashes(ctx, boards)
+	if err != nil {
+		return nil, fmt.Errorf("can't list media hashes: %w", err)
+	}
+	own := slices.IndexFunc(mhs, func(mh models.MediaHash) bool { return mh.ID == models.MediaID(meme.ID) })
+	if own < 0 || mhs[own].Hashes == nil {
+		return nil, nil
+	}
+
+	type similar struct {
+		id       models.MemeID
+		distance int
+	}
+	found := []similar{}
+	for _, mh := range mhs {
+		if mh.Hashes == nil || mh.ID == mhs[own].ID {
+			continue
+		}
+		if d := mhs[own].Hashes.Distance(*mh.Hashes); d <= similarMediaDistance {
+			found = append(found, similar{id: models.MemeID(mh.ID), distance: d})
+		}
+	}
+	slices.SortFunc(found, func(a, b similar) int {
+		return cmp.Or(cmp.Compare(a.distance, b.distance), cmp.Compare(a.id, b.id))
+	})
+
+	weights := map[string]float64{}
+	for _, s := range found[:min(maxSimilarMemes, len(found))] {
+		m, err := a.storage.GetMemeByID(ctx, s.id)
+		if errors.Is(err, models.ErrMemeNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't get meme %s: %w", s.id, err)
+		}
+		seen := map[string]struct{}{}
+		for _, d := range m.Description {
+			for _, w := range searchranker.Words(d) {
+				if _, ok := seen[w]; !ok {
+					seen[w] = struct{}{}
+					weights[w] += 1 - float64(s.distance)/64
+				}
+			}
+		}
+	}
+
+	words := make([]string, 0, len(weights))
+	for w := range weights {
+		words = append(words, w)
+	}
+	slices.SortFunc(words, func(a, b string) int {
+		return cmp.Or(cmp.Compare(weights[b], weights[a]), cmp.Compare(a, b))
+	})
+	return words, nil
+}
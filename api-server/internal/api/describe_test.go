@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"memesearch/internal/imagehash"
+	"memesearch/internal/models"
+	"memesearch/internal/searchindex"
+	"memesearch/internal/searchranker"
+	"memesearch/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestDescription(t *testing.T) {
+	boards := boardRepo{boards: map[models.BoardID]models.Board{
+		"a": {ID: "a", Owner: "alice"},
+		"x": {ID: "x", Owner: "bob"},
+	}}
+	memes := map[models.MemeID]models.Meme{
+		"1": {ID: "1", BoardID: "a", Description: map[string]string{"general": "кот"}},
+		"2": {ID: "2", BoardID: "a", Description: map[string]string{"general": "Кота спит диван"}},
+		"3": {ID: "3", BoardID: "a", Description: map[string]string{"general": "диван шапка", "text": "кот"}},
+		"4": {ID: "4", BoardID: "a", Description: map[string]string{"general": "собака диван"}},
+		"5": {ID: "5", BoardID: "x", Description: map[string]string{"general": "секрет"}},
+	}
+	// distances to the media of meme 1 are 0, 2, 6, 20 and 0
+	hashes := map[models.MediaID]models.MediaHash{
+		"1": {ID: "1", Hashes: &imagehash.Hashes{}},
+		"2": {ID: "2", Hashes: &imagehash.Hashes{DHash: 0b11, PHash: 0b11}},
+		"3": {ID: "3", Hashes: &imagehash.Hashes{DHash: 0b111111, PHash: 0b111111}},
+		"4": {ID: "4", Hashes: &imagehash.Hashes{DHash: 1<<20 - 1, PHash: 1<<20 - 1}},
+		"5": {ID: "5", Hashes: &imagehash.Hashes{}},
+	}
+	s := &mediaStore{memes: memes, hashes: hashes}
+	index := searchindex.New(searchranker.DefaultAnalyzer)
+	for _, m := range memes {
+		index.Add(m)
+	}
+	a := testApi(storage.Storage{BoardRepo: boards, MemeRepo: memeRepo{memes: memes}, MediaHashRepo: s}, &searcher{})
+	a.index = index
+	ctx := context.WithValue(context.Background(), contextKey("user_id"), models.UserID("alice"))
+
+	t.Run("Similar media first", func(t *testing.T) {
+		sugs, err := a.SuggestDescription(ctx, "1", 10)
+		require.NoError(t, err)
+		// words of nearer memes and of more memes weigh more, words of the
+		// description, forms of them and words of invisible boards are left out
+		assert.Equal(t, []DescriptionSuggestion{
+			{Word: "диван", Source: SuggestionSourceSimilar},
+			{Word: "спит", Source: SuggestionSourceSimilar},
+			{Word: "шапка", Source: SuggestionSourceSimilar},
+			{Word: "собака", Source: SuggestionSourceBoard},
+		}, sugs)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		sugs, err := a.SuggestDescription(ctx, "1", 2)
+		require.NoError(t, err)
+		assert.Equal(t, []DescriptionSuggestion{
+			{Word: "диван", Source: SuggestionSourceSimilar},
+			{Word: "спит", Source: SuggestionSourceSimilar},
+		}, sugs)
+	})
+
+	t.Run("Without media hash", func(t *testing.T) {
+		memes["6"] = models.Meme{ID: "6", BoardID: "a", Description: map[string]string{"general": "диван"}}
+		defer delete(memes, "6")
+		words, err := a.similarMediaWords(ctx, memes["6"])
+		require.NoError(t, err)
+		assert.Empty(t, words)
+	})
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"errors"
 	"fmt"
+	"memesearch/internal/models"
 )
 
 var (
@@ -33,3 +34,15 @@ func (e ErrInvalid) Is(target error) bool {
 		(t.Param == "" || t.Param == e.Param)
 }
 
+// ErrDuplicate is returned when the board already has the same or a
+// near-identical media.
+type ErrDuplicate struct {
+	// Meme is the meme which has the media.
+	Meme models.MemeID
+	// Exact is set when the media content is the same.
+	Exact bool
+}
+
+func (e ErrDuplicate) Error() string {
+	return fmt.Sprintf("DUPLICATE_MEDIA: media is already used by meme %s", e.Meme)
+}
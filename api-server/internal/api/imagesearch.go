@@ -0,0 +1,72 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"memesearch/internal/imagehash"
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+	"slices"
+)
+
+// SearchByImage returns memes on visible boards whose media looks like image,
+// nearest first. Memes with hashes farther than maxDistance are skipped.
+func (a *api) SearchByImage(ctx context.Context, image []byte, boards []models.BoardID, maxDistance, offset, limit int) ([]searchranker.ScroredMeme, error) {
+	logger := slog.Default().With("from", "api.SearchByImage")
+	logger.InfoContext(ctx, "Started")
+
+	hashes, err := imagehash.Compute(image)
+	if err != nil {
+		if errors.Is(err, imagehash.ErrUnsupported) {
+			return nil, ErrInvalid{Param: "media", Reason: "JPEG or PNG image is expected"}
+		}
+		if errors.Is(err, imagehash.ErrTooLarge) {
+			return nil, ErrInvalid{Param: "media", Reason: fmt.Sprintf("image must have at most %d pixels", imagehash.MaxPixels)}
+		}
+		return nil, fmt.Errorf("can't hash image: %w", err)
+	}
+
+	userID := GetUserID(ctx)
+	if userID == "" {
+		userID = "guest"
+	}
+	visible, err := a.visibleBoards(ctx, userID, models.MemeFilter{Boards: boards})
+	if err != nil {
+		return nil, fmt.Errorf("can't get visible boards: %w", err)
+	}
+	mhs, err := a.storage.ListMediaHashes(ctx, visible)
+	if err != nil {
+		return nil, fmt.Errorf("can't list media hashes: %w", err)
+	}
+
+	type similar struct {
+		id       models.MemeID
+		distance int
+	}
+	found := []similar{}
+	for _, mh := range mhs {
+		if mh.Hashes == nil {
+			continue
+		}
+		if d := hashes.Distance(*mh.Hashes); d <= maxDistance {
+			found = append(found, similar{id: models.MemeID(mh.ID), distance: d})
+		}
+	}
+	slices.SortFunc(found, func(a, b similar) int {
+		return cmp.Or(cmp.Compare(a.distance, b.distance), cmp.Compare(a.id, b.id))
+	})
+
+	found = found[min(offset, len(found)):min(offset+limit, len(found))]
+	res := make([]searchranker.ScroredMeme, 0, len(found))
+	for _, s := range found {
+		meme, err := a.storage.GetMemeByID(ctx, s.id)
+		if err != nil {
+			return nil, fmt.Errorf("can't get meme %s: %w", s.id, err)
+		}
+		res = append(res, searchranker.ScroredMeme{Score: 1 - float64(s.distance)/64, Meme: meme})
+	}
+	return res, nil
+}
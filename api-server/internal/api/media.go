@@ -2,9 +2,12 @@ package api
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log/slog"
+	"memesearch/internal/imagehash"
 	"memesearch/internal/models"
 )
 
@@ -25,14 +28,124 @@ func (a *api) GetMedia(ctx context.Context, id models.MediaID) (models.Media, er
 	return media, nil
 }
 
-func (a *api) SetMedia(ctx context.Context, media models.Media) error {
+// duplicateDistance is the maximum distance between perceptual hashes
+// of near-identical images.
+const duplicateDistance = 4
+
+// SetMedia stores media of a meme. Unless force is set it fails with
+// ErrDuplicate when another meme of the board already has the same or
+// a near-identical media.
+func (a *api) SetMedia(ctx context.Context, media models.Media, force bool) error {
 	logger := slog.Default().With("from", "api.SetMedia")
 	logger.InfoContext(ctx, "Started", "id", media.ID)
 
-	err := a.storage.SetMediaByID(ctx, media)
+	hash := hashMedia(ctx, media)
+	hash.Forced = force
+	if !force {
+		if err := a.checkDuplicate(ctx, hash); err != nil {
+			return err
+		}
+	}
+
+	// the hash is stored first, so that the same media set concurrently
+	// is rejected by storage before it replaces the media
+	err := a.storage.SetMediaHash(ctx, hash)
+	if errors.Is(err, models.ErrMediaHashExists) {
+		if err := a.checkDuplicate(ctx, hash); err != nil {
+			return err
+		}
+		return ErrDuplicate{Exact: true}
+	}
+	if err != nil {
+		return fmt.Errorf("can't set media hash: %w", err)
+	}
+	err = a.storage.SetMediaByID(ctx, media)
 	if err != nil {
 		return fmt.Errorf("can't set media: %w", err)
 	}
 
 	return nil
 }
+
+// hashMedia computes the content hash of media and perceptual hashes of it
+// when it is an image.
+func hashMedia(ctx context.Context, media models.Media) models.MediaHash {
+	sum := sha256.Sum256(media.Body)
+	hash := models.MediaHash{ID: media.ID, SHA256: hex.EncodeToString(sum[:])}
+	hashes, err := imagehash.Compute(media.Body)
+	if err == nil {
+		hash.Hashes = &hashes
+	} else {
+		// videos and images which can't be decoded are compared by content only
+		slog.InfoContext(ctx, "Media isn't an image", "id", media.ID, "err", err)
+	}
+	return hash
+}
+
+// BackfillMediaHashes hashes media stored before hashes were kept or whose
+// hash failed to be saved. Memes without media are skipped. It returns the
+// number of hashed media.
+func (a *api) BackfillMediaHashes(ctx context.Context) (int, error) {
+	logger := slog.Default().With("from", "api.BackfillMediaHashes")
+	logger.InfoContext(ctx, "Started")
+
+	const batchSize = 100
+	hashed := 0
+	var after models.MediaID
+	for {
+		ids, err := a.storage.ListUnhashedMedia(ctx, after, batchSize)
+		if err != nil {
+			return hashed, fmt.Errorf("can't list unhashed media: %w", err)
+		}
+		for _, id := range ids {
+			media, err := a.storage.GetMediaByID(ctx, id)
+			if errors.Is(err, models.ErrMediaNotFound) {
+				continue
+			}
+			if err != nil {
+				return hashed, fmt.Errorf("can't get media %s: %w", id, err)
+			}
+			// duplicates stored before hashes were kept are allowed
+			hash := hashMedia(ctx, media)
+			hash.Forced = true
+			if err := a.storage.SetMediaHash(ctx, hash); err != nil {
+				return hashed, fmt.Errorf("can't set media hash %s: %w", id, err)
+			}
+			hashed++
+		}
+		if len(ids) < batchSize {
+			return hashed, nil
+		}
+		after = ids[len(ids)-1]
+	}
+}
+
+// checkDuplicate returns ErrDuplicate when another meme of the board
+// of the meme with hash has the same or a near-identical media.
+func (a *api) checkDuplicate(ctx context.Context, hash models.MediaHash) error {
+	meme, err := a.storage.GetMemeByID(ctx, models.MemeID(hash.ID))
+	if err != nil {
+		return fmt.Errorf("can't get meme: %w", err)
+	}
+	mhs, err := a.storage.ListMediaHashes(ctx, []models.BoardID{meme.BoardID})
+	if err != nil {
+		return fmt.Errorf("can't list media hashes: %w", err)
+	}
+
+	var near *ErrDuplicate
+	for _, mh := range mhs {
+		if mh.ID == hash.ID {
+			continue
+		}
+		if mh.SHA256 == hash.SHA256 {
+			return ErrDuplicate{Meme: models.MemeID(mh.ID), Exact: true}
+		}
+		if near == nil && hash.Hashes != nil && mh.Hashes != nil && hash.Hashes.Distance(*mh.Hashes) <= duplicateDistance {
+			near = &ErrDuplicate{Meme: models.MemeID(mh.ID)}
+		}
+	}
+	if near != nil {
+		return *near
+	}
+	return nil
+}
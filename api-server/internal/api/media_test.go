@@ -0,0 +1,208 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"memesearch/internal/models"
+	"memesearch/internal/storage"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memeRepo serves memes from a map, other methods panic.
+type memeRepo struct {
+	models.MemeRepo
+	memes map[models.MemeID]models.Meme
+}
+
+func (r memeRepo) GetMemeByID(ctx context.Context, id models.MemeID) (models.Meme, error) {
+	m, ok := r.memes[id]
+	if !ok {
+		return models.Meme{}, models.ErrMemeNotFound
+	}
+	return m, nil
+}
+
+// mediaStore keeps media and their hashes in maps. Unforced hashes are
+// unique per board like in the database.
+type mediaStore struct {
+	memes  map[models.MemeID]models.Meme
+	media  map[models.MediaID][]byte
+	hashes map[models.MediaID]models.MediaHash
+	// hidden is the number of ListMediaHashes calls which miss every hash
+	// as if they were stored concurrently.
+	hidden int
+}
+
+func (s *mediaStore) GetMediaByID(ctx context.Context, id models.MediaID) (models.Media, error) {
+	body, ok := s.media[id]
+	if !ok {
+		return models.Media{}, models.ErrMediaNotFound
+	}
+	return models.Media{ID: id, Body: body}, nil
+}
+
+func (s *mediaStore) SetMediaByID(ctx context.Context, media models.Media) error {
+	s.media[media.ID] = media.Body
+	return nil
+}
+
+func (s *mediaStore) SetMediaHash(ctx context.Context, hash models.MediaHash) error {
+	board := s.memes[models.MemeID(hash.ID)].BoardID
+	for id, h := range s.hashes {
+		if id != hash.ID && !hash.Forced && !h.Forced && h.SHA256 == hash.SHA256 && s.memes[models.MemeID(id)].BoardID == board {
+			return models.ErrMediaHashExists
+		}
+	}
+	s.hashes[hash.ID] = hash
+	return nil
+}
+
+func (s *mediaStore) ListMediaHashes(ctx context.Context, boards []models.BoardID) ([]models.MediaHash, error) {
+	res := []models.MediaHash{}
+	if s.hidden > 0 {
+		s.hidden--
+		return res, nil
+	}
+	for id, h := range s.hashes {
+		for _, b := range boards {
+			if s.memes[models.MemeID(id)].BoardID == b {
+				res = append(res, h)
+			}
+		}
+	}
+	return res, nil
+}
+
+func (s *mediaStore) ListUnhashedMedia(ctx context.Context, after models.MediaID, limit int) ([]models.MediaID, error) {
+	res := []models.MediaID{}
+	for id := range s.memes {
+		if _, ok := s.hashes[models.MediaID(id)]; !ok && models.MediaID(id) > after {
+			res = append(res, models.MediaID(id))
+		}
+	}
+	slices.Sort(res)
+	return res[:min(limit, len(res))], nil
+}
+
+func gradient(w, h int, flip bool) []byte {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			v := uint8(x * 255 / w)
+			if flip {
+				v = 255 - v
+			}
+			img.SetGray(x, y, color.Gray{Y: v ^ uint8(y*255/h)})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSetMedia(t *testing.T) {
+	ctx := context.Background()
+	memes := map[models.MemeID]models.Meme{
+		"1": {ID: "1", BoardID: "a"},
+		"2": {ID: "2", BoardID: "a"},
+		"3": {ID: "3", BoardID: "b"},
+	}
+	newApiWith := func() (*api, *mediaStore) {
+		s := &mediaStore{memes: memes, media: map[models.MediaID][]byte{}, hashes: map[models.MediaID]models.MediaHash{}}
+		a := testApi(storage.Storage{MemeRepo: memeRepo{memes: memes}, MediaRepo: s, MediaHashRepo: s}, &searcher{})
+		return a, s
+	}
+	orig := gradient(64, 64, false)
+	scaled := gradient(128, 128, false)
+	other := gradient(64, 64, true)
+
+	t.Run("Exact", func(t *testing.T) {
+		a, _ := newApiWith()
+		require.NoError(t, a.SetMedia(ctx, models.Media{ID: "1", Body: orig}, false))
+		err := a.SetMedia(ctx, models.Media{ID: "2", Body: orig}, false)
+		assert.Equal(t, ErrDuplicate{Meme: "1", Exact: true}, err)
+	})
+
+	t.Run("Near", func(t *testing.T) {
+		a, _ := newApiWith()
+		require.NoError(t, a.SetMedia(ctx, models.Media{ID: "1", Body: orig}, false))
+		err := a.SetMedia(ctx, models.Media{ID: "2", Body: scaled}, false)
+		assert.Equal(t, ErrDuplicate{Meme: "1"}, err)
+		assert.NoError(t, a.SetMedia(ctx, models.Media{ID: "2", Body: other}, false))
+	})
+
+	t.Run("Other board", func(t *testing.T) {
+		a, _ := newApiWith()
+		require.NoError(t, a.SetMedia(ctx, models.Media{ID: "1", Body: orig}, false))
+		assert.NoError(t, a.SetMedia(ctx, models.Media{ID: "3", Body: orig}, false))
+	})
+
+	t.Run("Same meme", func(t *testing.T) {
+		a, _ := newApiWith()
+		require.NoError(t, a.SetMedia(ctx, models.Media{ID: "1", Body: orig}, false))
+		assert.NoError(t, a.SetMedia(ctx, models.Media{ID: "1", Body: orig}, false))
+	})
+
+	t.Run("Force", func(t *testing.T) {
+		a, s := newApiWith()
+		require.NoError(t, a.SetMedia(ctx, models.Media{ID: "1", Body: orig}, false))
+		require.NoError(t, a.SetMedia(ctx, models.Media{ID: "2", Body: orig}, true))
+		assert.Equal(t, orig, s.media["2"])
+		assert.Equal(t, s.hashes["1"].SHA256, s.hashes["2"].SHA256)
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		a, s := newApiWith()
+		require.NoError(t, a.SetMedia(ctx, models.Media{ID: "1", Body: orig}, false))
+		s.hidden = 1
+		err := a.SetMedia(ctx, models.Media{ID: "2", Body: orig}, false)
+		assert.Equal(t, ErrDuplicate{Meme: "1", Exact: true}, err)
+		assert.NotContains(t, s.media, models.MediaID("2"))
+	})
+
+	t.Run("Not an image", func(t *testing.T) {
+		a, s := newApiWith()
+		truncated := orig[:len(orig)/2]
+		require.NoError(t, a.SetMedia(ctx, models.Media{ID: "1", Body: truncated}, false))
+		assert.Nil(t, s.hashes["1"].Hashes)
+		// the content is still compared
+		err := a.SetMedia(ctx, models.Media{ID: "2", Body: truncated}, false)
+		assert.Equal(t, ErrDuplicate{Meme: "1", Exact: true}, err)
+	})
+}
+
+func TestBackfillMediaHashes(t *testing.T) {
+	ctx := context.Background()
+	memes := map[models.MemeID]models.Meme{}
+	s := &mediaStore{memes: memes, media: map[models.MediaID][]byte{}, hashes: map[models.MediaID]models.MediaHash{}}
+	for i := range 250 {
+		id := models.MemeID(fmt.Sprintf("%03d", i))
+		memes[id] = models.Meme{ID: id, BoardID: "a"}
+		// every tenth meme has no media
+		if i%10 != 0 {
+			s.media[models.MediaID(id)] = []byte(id)
+		}
+	}
+	s.hashes["001"] = models.MediaHash{ID: "001", SHA256: "kept"}
+	a := testApi(storage.Storage{MemeRepo: memeRepo{memes: memes}, MediaRepo: s, MediaHashRepo: s}, &searcher{})
+
+	n, err := a.BackfillMediaHashes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 224, n)
+	assert.Len(t, s.hashes, 225)
+	assert.Equal(t, "kept", s.hashes["001"].SHA256)
+	want := hashMedia(ctx, models.Media{ID: "002", Body: []byte("002")})
+	want.Forced = true
+	assert.Equal(t, want, s.hashes["002"])
+	assert.NotContains(t, s.hashes, models.MediaID("010"))
+}
@@ -14,6 +14,8 @@ func (a *api) CreateMeme(ctx context.Context, board models.BoardID, filename str
 	if err != nil {
 		return models.Meme{}, fmt.Errorf("can't create meme: %w", err)
 	}
+	a.cache.invalidateBoards(board)
+
 	meme, err = a.storage.GetMemeByID(ctx, id)
 	if err != nil {
 		return models.Meme{}, fmt.Errorf("can't get meme: %w", err)
@@ -42,6 +44,7 @@ func (a *api) UpdateMeme(ctx context.Context, id models.MemeID, board *models.Bo
 	if err != nil {
 		return models.Meme{}, fmt.Errorf("can't get meme: %w", err)
 	}
+	prevBoard := meme.BoardID
 	if dsc != nil {
 		meme.Description = *dsc
 	}
@@ -61,6 +64,7 @@ func (a *api) UpdateMeme(ctx context.Context, id models.MemeID, board *models.Bo
 			return models.Meme{}, fmt.Errorf("can't update meme: %w", err)
 		}
 	}
+	a.cache.invalidateBoards(prevBoard, meme.BoardID)
 
 	meme, err = a.GetMemeByID(ctx, id)
 	if err != nil {
@@ -74,7 +78,11 @@ func (a *api) DeleteMeme(ctx context.Context, id models.MemeID) error {
 	logger := slog.Default().With("from", "api.DeleteMeme")
 	logger.InfoContext(ctx, "Started", "id", id)
 
-	err := a.storage.DeleteMeme(ctx, id)
+	meme, err := a.GetMemeByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = a.storage.DeleteMeme(ctx, id)
 	if err != nil {
 		switch {
 		case errors.Is(err, models.ErrMemeNotFound):
@@ -83,19 +91,32 @@ func (a *api) DeleteMeme(ctx context.Context, id models.MemeID) error {
 			return fmt.Errorf("can't delete meme: %w", err)
 		}
 	}
+	a.cache.invalidateBoards(meme.BoardID)
 	return nil
 }
 
-func (a *api) ListMemes(ctx context.Context, offset, limit int, sortBy string) ([]models.Meme, error) {
+func (a *api) ListMemes(ctx context.Context, filter models.MemeFilter, offset, limit int, sortBy string) ([]models.Meme, error) {
 	userID := GetUserID(ctx)
 	if userID == "" {
 		userID = "guest"
 	}
 
-	memes, err := a.storage.ListMemes(ctx, userID, offset, limit, sortBy)
+	memes, err := a.storage.ListMemes(ctx, userID, filter, offset, limit, sortBy)
 	if err != nil {
 		return nil, fmt.Errorf("can't list memes: %w", err)
 	}
 
 	return memes, nil
 }
+
+// ListBoardMemes lists memes of the board ordered by ID.
+func (a *api) ListBoardMemes(ctx context.Context, id models.BoardID, offset, limit int) ([]models.Meme, error) {
+	if _, err := a.GetBoardByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("can't get board: %w", err)
+	}
+	memes, err := a.storage.GetMemesByBoardID(ctx, id, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("can't list memes: %w", err)
+	}
+	return memes, nil
+}
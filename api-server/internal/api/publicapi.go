@@ -5,20 +5,18 @@ import (
 	"fmt"
 	"memesearch/internal/config"
 	"memesearch/internal/models"
+	"memesearch/internal/searchindex"
 	"memesearch/internal/searchranker"
 	"memesearch/internal/storage"
+	"time"
 )
 
 type API struct {
 	api *api
 }
 
-func New(s storage.Storage, secrets config.SecretConfig, ranker searchranker.Ranker) *API {
-	return &API{&api{
-		storage: s,
-		secrets: secrets,
-		ranker:  ranker,
-	}}
+func New(s storage.Storage, secrets config.SecretConfig, ranker searchranker.Ranker, index *searchindex.Index, analyzer searchranker.Analyzer, cfg config.SearchConfig) *API {
+	return &API{newApi(s, secrets, ranker, index, analyzer, cfg)}
 }
 
 func (a *API) CreateBoard(ctx context.Context, name string) (models.Board, error) {
@@ -56,6 +54,20 @@ func (a *API) DeleteBoard(ctx context.Context, id models.BoardID) (models.Board,
 	return a.api.DeleteBoard(ctx, id)
 }
 
+func (a *API) GetBoardSynonyms(ctx context.Context, id models.BoardID) ([][]string, error) {
+	if err := a.aclGetBoard(ctx, id); err != nil {
+		return nil, fmt.Errorf("acl failed: %w", err)
+	}
+	return a.api.GetBoardSynonyms(ctx, id)
+}
+
+func (a *API) SetBoardSynonyms(ctx context.Context, id models.BoardID, groups [][]string) ([][]string, error) {
+	if err := a.aclUpdateBoard(ctx, id); err != nil {
+		return nil, fmt.Errorf("acl failed: %w", err)
+	}
+	return a.api.SetBoardSynonyms(ctx, id, groups)
+}
+
 func (a *API) ListBoards(ctx context.Context, offset, limit int, sortBy string) ([]models.Board, error) {
 	if GetUserID(ctx) == "" {
 		return nil, ErrUnauthorized
@@ -70,11 +82,11 @@ func (a *API) GetMedia(ctx context.Context, id models.MediaID) (models.Media, er
 	return a.api.GetMedia(ctx, id)
 }
 
-func (a *API) SetMedia(ctx context.Context, media models.Media) error {
+func (a *API) SetMedia(ctx context.Context, media models.Media, force bool) error {
 	if err := a.aclUpdateMedia(ctx, media.ID); err != nil {
 		return fmt.Errorf("acl failed: %w", err)
 	}
-	return a.api.SetMedia(ctx, media)
+	return a.api.SetMedia(ctx, media, force)
 }
 
 func (a *API) CreateMeme(ctx context.Context, board models.BoardID, filename string, dsc map[string]string) (models.Meme, error) {
@@ -95,6 +107,13 @@ func (a *API) GetMemeByID(ctx context.Context, id models.MemeID) (models.Meme, e
 	return a.api.GetMemeByID(ctx, id)
 }
 
+func (a *API) AddUsage(ctx context.Context, id models.MemeID, source string) error {
+	if err := a.aclGetMeme(ctx, id); err != nil {
+		return fmt.Errorf("acl failed: %w", err)
+	}
+	return a.api.AddUsage(ctx, id, source)
+}
+
 func (a *API) UpdateMeme(ctx context.Context, id models.MemeID, board *models.BoardID, filename *string, dsc *map[string]string) (models.Meme, error) {
 	if board != nil {
 		if err := a.validateBoard(ctx, *board, "meme's board"); err != nil {
@@ -115,8 +134,15 @@ func (a *API) DeleteMeme(ctx context.Context, id models.MemeID) error {
 	return a.api.DeleteMeme(ctx, id)
 }
 
-func (a *API) ListMemes(ctx context.Context, offset, limit int, sortBy string) ([]models.Meme, error) {
-	return a.api.ListMemes(ctx, offset, limit, sortBy)
+func (a *API) ListMemes(ctx context.Context, filter models.MemeFilter, offset, limit int, sortBy string) ([]models.Meme, error) {
+	return a.api.ListMemes(ctx, filter, offset, limit, sortBy)
+}
+
+func (a *API) ListBoardMemes(ctx context.Context, id models.BoardID, offset, limit int) ([]models.Meme, error) {
+	if err := a.aclGetBoard(ctx, id); err != nil {
+		return nil, fmt.Errorf("acl failed: %w", err)
+	}
+	return a.api.ListBoardMemes(ctx, id, offset, limit)
 }
 
 func (a *API) Unsubscribe(ctx context.Context, user models.UserID, board models.BoardID, role string) error {
@@ -126,6 +152,14 @@ func (a *API) Unsubscribe(ctx context.Context, user models.UserID, board models.
 	return a.api.Unsubscribe(ctx, user, board, role)
 }
 
+func (a *API) SetSubscriptionWeight(ctx context.Context, board models.BoardID, weight float64) error {
+	userID := GetUserID(ctx)
+	if userID == "" {
+		return ErrUnauthorized
+	}
+	return a.api.SetSubscriptionWeight(ctx, userID, board, weight)
+}
+
 func (a *API) Subscribe(ctx context.Context, user models.UserID, board models.BoardID, role string) error {
 	if _, err := a.GetBoardByID(ctx, board); err != nil {
 		return fmt.Errorf("can't get board: %w", err)
@@ -173,6 +207,58 @@ func (a *API) Authorize(ctx context.Context, token string) (context.Context, err
 	return a.api.Authorize(ctx, token)
 }
 
-func (a *API) Search(ctx context.Context, req map[string]string, offset, limit int) ([]searchranker.ScroredMeme, error) {
-	return a.api.Search(ctx, req, offset, limit)
+func (a *API) Search(ctx context.Context, req map[string]string, filter models.MemeFilter, explain bool, offset, limit int) (SearchPage, error) {
+	return a.api.Search(ctx, req, filter, explain, offset, limit)
+}
+
+func (a *API) SearchBoard(ctx context.Context, id models.BoardID, req map[string]string, filter models.MemeFilter, explain bool, offset, limit int) (SearchPage, error) {
+	if err := a.aclGetBoard(ctx, id); err != nil {
+		return SearchPage{}, fmt.Errorf("acl failed: %w", err)
+	}
+	return a.api.SearchBoard(ctx, id, req, filter, explain, offset, limit)
+}
+
+func (a *API) FrequentQueries(ctx context.Context, board models.BoardID, since time.Time, limit int) ([]models.QueryStat, error) {
+	if err := a.aclUpdateBoard(ctx, board); err != nil {
+		return nil, fmt.Errorf("acl failed: %w", err)
+	}
+	return a.api.FrequentQueries(ctx, board, since, limit)
+}
+
+func (a *API) FailedQueries(ctx context.Context, board models.BoardID, since time.Time, maxScore *float64, limit int) ([]models.QueryStat, error) {
+	if err := a.aclUpdateBoard(ctx, board); err != nil {
+		return nil, fmt.Errorf("acl failed: %w", err)
+	}
+	return a.api.FailedQueries(ctx, board, since, maxScore, limit)
+}
+
+func (a *API) BackfillMediaHashes(ctx context.Context) (int, error) {
+	return a.api.BackfillMediaHashes(ctx)
+}
+
+func (a *API) SearchCacheStats() CacheStats {
+	return a.api.SearchCacheStats()
+}
+
+func (a *API) SearchLogStats() SearchLogStats {
+	return a.api.SearchLogStats()
+}
+
+func (a *API) SearchNext(ctx context.Context, board models.BoardID, cursor string, limit int) (SearchPage, error) {
+	return a.api.SearchNext(ctx, board, cursor, limit)
+}
+
+func (a *API) SearchByImage(ctx context.Context, image []byte, boards []models.BoardID, maxDistance, offset, limit int) ([]searchranker.ScroredMeme, error) {
+	return a.api.SearchByImage(ctx, image, boards, maxDistance, offset, limit)
+}
+
+func (a *API) SuggestDescription(ctx context.Context, id models.MemeID, limit int) ([]DescriptionSuggestion, error) {
+	if err := a.aclGetMeme(ctx, id); err != nil {
+		return nil, fmt.Errorf("acl failed: %w", err)
+	}
+	return a.api.SuggestDescription(ctx, id, limit)
+}
+
+func (a *API) Suggest(ctx context.Context, prefix string, limit int) (Suggestions, error) {
+	return a.api.Suggest(ctx, prefix, limit)
 }
@@ -2,19 +2,122 @@ package api
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"log/slog"
 	"memesearch/internal/models"
 	"memesearch/internal/searchranker"
+	"time"
 )
 
-func (a *api) Search(ctx context.Context, req map[string]string, offset, limit int) ([]searchranker.ScroredMeme, error) {
+// SearchPage is a page of search results.
+type SearchPage struct {
+	Memes []searchranker.ScroredMeme
+	// Page is the number of the page starting from 0.
+	Page     int
+	PageSize int
+	// NextCursor continues the same results, it is empty on the last page.
+	NextCursor string
+}
+
+// Search ranks memes visible to the user and passing filter by relevance
+// to query. With explain set every result reports how the query terms matched it.
+func (a *api) Search(ctx context.Context, query map[string]string, filter models.MemeFilter, explain bool, offset, limit int) (SearchPage, error) {
 	logger := slog.Default().With("from", "api.SearchMemeByBoardID")
 	logger.InfoContext(ctx, "Started")
 
+	userID := GetUserID(ctx)
+	if userID == "" {
+		userID = "guest"
+	}
+
+	start := time.Now()
+	snap, err := a.search(ctx, userID, nil, query, filter, explain)
+	if err != nil {
+		return SearchPage{}, err
+	}
+	page, err := a.searchPage(ctx, "", snap, offset, limit)
+	if err != nil {
+		return SearchPage{}, err
+	}
+	a.logSearch(ctx, query, filter, snap, page, offset, time.Since(start))
+	return page, nil
+}
+
+// SearchBoard is Search over memes of the board only. The board is searched
+// whether or not the user is subscribed to it.
+func (a *api) SearchBoard(ctx context.Context, id models.BoardID, query map[string]string, filter models.MemeFilter, explain bool, offset, limit int) (SearchPage, error) {
+	logger := slog.Default().With("from", "api.SearchBoard")
+	logger.InfoContext(ctx, "Started")
+
+	userID := GetUserID(ctx)
+	if userID == "" {
+		userID = "guest"
+	}
+
+	board, err := a.GetBoardByID(ctx, id)
+	if err != nil {
+		return SearchPage{}, fmt.Errorf("can't get board: %w", err)
+	}
+	start := time.Now()
+	snap, err := a.search(ctx, userID, &board, query, filter, explain)
+	if err != nil {
+		return SearchPage{}, err
+	}
+	page, err := a.searchPage(ctx, "", snap, offset, limit)
+	if err != nil {
+		return SearchPage{}, err
+	}
+	a.logSearch(ctx, query, filter, snap, page, offset, time.Since(start))
+	return page, nil
+}
+
+// SearchNext returns the page of results of an earlier search the cursor
+// points to. Cursors of SearchBoard are accepted only with the same board,
+// cursors of Search only with an empty one.
+func (a *api) SearchNext(ctx context.Context, board models.BoardID, cursor string, limit int) (SearchPage, error) {
+	logger := slog.Default().With("from", "api.SearchNext")
+	logger.InfoContext(ctx, "Started")
+
+	userID := GetUserID(ctx)
+	if userID == "" {
+		userID = "guest"
+	}
+
+	id, cursorBoard, offset, ok := decodeCursor(cursor)
+	if !ok {
+		return SearchPage{}, ErrInvalid{Param: "cursor", Reason: "malformed cursor"}
+	}
+	if cursorBoard != board {
+		return SearchPage{}, ErrInvalid{Param: "cursor", Reason: "cursor of another search"}
+	}
+	snap, ok := a.cursors.Get(id)
+	if !ok || snap.userID != userID || snap.board != board {
+		return SearchPage{}, ErrInvalid{Param: "cursor", Reason: "unknown or expired cursor"}
+	}
+	return a.searchPage(ctx, id, snap, offset, limit)
+}
+
+// search runs query and returns its results to be paged. Memes of boards
+// visible to the user are searched unless board is set.
+func (a *api) search(ctx context.Context, userID models.UserID, board *models.Board, query map[string]string, filter models.MemeFilter, explain bool) (*searchSnapshot, error) {
+	snap := &searchSnapshot{userID: userID}
+	if board != nil {
+		snap.board = board.ID
+		snap.boards = []models.BoardID{board.ID}
+	}
+	load := func(ctx context.Context, offset, limit int) ([]models.Meme, error) {
+		return a.storage.ListMemes(ctx, userID, filter, offset, limit, "id")
+	}
+	if board != nil {
+		load = func(ctx context.Context, offset, limit int) ([]models.Meme, error) {
+			return a.storage.GetMemesByBoardID(ctx, board.ID, offset, limit)
+		}
+	}
+
 	isEmpty := true
-	if len(req) > 0 {
-		for _, v := range req {
+	if len(query) > 0 {
+		for _, v := range query {
 			if len(v) != 0 {
 				isEmpty = false
 				break
@@ -22,41 +125,146 @@ func (a *api) Search(ctx context.Context, req map[string]string, offset, limit i
 		}
 	}
 
+	if isEmpty && board != nil {
+		snap.list = func(ctx context.Context, offset, limit int) ([]searchranker.ScroredMeme, error) {
+			return listFiltered(ctx, load, searchranker.Query{}, filter, offset, limit)
+		}
+		return snap, nil
+	}
 	if isEmpty {
-		memes, err := a.ListMemes(ctx, offset, limit, "id")
+		snap.list = func(ctx context.Context, offset, limit int) ([]searchranker.ScroredMeme, error) {
+			memes, err := a.ListMemes(ctx, filter, offset, limit, "id")
+			if err != nil {
+				return nil, fmt.Errorf("can't list memes: %w", err)
+			}
+			smemes := make([]searchranker.ScroredMeme, 0, len(memes))
+			for _, m := range memes {
+				smemes = append(smemes, searchranker.ScroredMeme{Score: 0, Meme: m})
+			}
+			return smemes, nil
+		}
+		return snap, nil
+	}
+
+	q, err := searchranker.ParseQuery(query, a.analyzer, a.fields)
+	if err != nil {
+		return nil, ErrInvalid{Param: "query", Reason: err.Error()}
+	}
+
+	if len(q.Clauses) == 0 {
+		snap.list = func(ctx context.Context, offset, limit int) ([]searchranker.ScroredMeme, error) {
+			return listFiltered(ctx, load, q, filter, offset, limit)
+		}
+		return snap, nil
+	}
+
+	key := searchKey(userID, query, filter, explain)
+	if board != nil {
+		key += "\x00board=" + string(board.ID)
+	}
+	if cached, ok := a.cache.get(key); ok {
+		return cached, nil
+	}
+
+	var scope models.BoardID
+	visible := []models.Board{}
+	if board != nil {
+		scope = board.ID
+		visible = append(visible, *board)
+	} else {
+		visible, err = a.listVisibleBoards(ctx, userID, filter)
 		if err != nil {
-			return nil, fmt.Errorf("can't list memes: %w", err)
+			return nil, fmt.Errorf("can't get visible boards: %w", err)
+		}
+	}
+	snap.results, err = a.rank(ctx, userID, scope, visible, q, filter, explain)
+	if err != nil {
+		return nil, fmt.Errorf("can't rank: %w", err)
+	}
+	if board == nil {
+		for _, b := range visible {
+			snap.boards = append(snap.boards, b.ID)
 		}
-		smemes := make([]searchranker.ScroredMeme, 0, len(memes))
-		for _, m := range memes {
-			smemes = append(smemes, searchranker.ScroredMeme{Score: 0, Meme: m})
+	}
+	a.cache.add(key, snap)
+	return snap, nil
+}
+
+// searchPage returns limit results of snap starting from offset. Unless it is
+// the last page snap is kept under id, a new id is generated when it is empty.
+func (a *api) searchPage(ctx context.Context, id string, snap *searchSnapshot, offset, limit int) (SearchPage, error) {
+	memes, more, err := snap.page(ctx, offset, limit)
+	if err != nil {
+		return SearchPage{}, err
+	}
+
+	page := SearchPage{Memes: memes, Page: offset / limit, PageSize: limit}
+	if more {
+		if id == "" {
+			id = rand.Text()
 		}
-		return smemes, nil
+		// adding again extends the TTL
+		a.cursors.Add(id, snap)
+		page.NextCursor = encodeCursor(id, snap.board, offset+limit)
+	}
+	logAttribution(ctx, snap.userID, offset, memes)
+	return page, nil
+}
+
+// logAttribution logs which experiment arm produced every shown result.
+func logAttribution(ctx context.Context, userID models.UserID, offset int, res []searchranker.ScroredMeme) {
+	if len(res) == 0 || res[0].Ranker == "" {
+		return
+	}
+	attr := make([]string, 0, len(res))
+	for _, m := range res {
+		attr = append(attr, string(m.Meme.ID)+":"+m.Ranker)
+	}
+	slog.InfoContext(ctx, "Experiment results", "user", userID, "offset", offset, "results", attr)
+}
+
+// rank returns all memes of visible boards passing filter ordered by relevance
+// to query. The only visible board is scope when it is set.
+func (a *api) rank(ctx context.Context, userID models.UserID, scope models.BoardID, visible []models.Board, query searchranker.Query, filter models.MemeFilter, explain bool) ([]searchranker.ScroredMeme, error) {
+	if e, ok := a.ranker.(*searchranker.Experiment); ok {
+		return e.Run(ctx, userID, query.Text(), func(r searchranker.Ranker) ([]searchranker.ScroredMeme, error) {
+			return a.rankWith(ctx, r, userID, scope, visible, query, filter, explain)
+		})
+	}
+	return a.rankWith(ctx, a.ranker, userID, scope, visible, query, filter, explain)
+}
+
+// rankWith ranks memes with ranker loading candidates from the index
+// unless ranker is a Searcher.
+func (a *api) rankWith(ctx context.Context, ranker searchranker.Ranker, userID models.UserID, scope models.BoardID, visible []models.Board, query searchranker.Query, filter models.MemeFilter, explain bool) ([]searchranker.ScroredMeme, error) {
+	if s, ok := ranker.(searchranker.Searcher); ok {
+		return s.Search(ctx, userID, searchranker.Request{Query: query, Filter: filter, Explain: explain, UserID: userID, Board: scope})
 	}
+	return a.index.Rank(ctx, ranker, visible, query, filter, searchranker.Request{Explain: explain, UserID: userID})
+}
 
-	batchSize := 200
-	memes := []models.Meme{}
-	listOffset := 0
-	for {
-		nmemes, err := a.ListMemes(ctx, listOffset, batchSize, "id")
+// listFiltered lists memes returned by load which pass filter and query
+// filters. It is used for queries without any searched words.
+func listFiltered(ctx context.Context, load func(ctx context.Context, offset, limit int) ([]models.Meme, error), query searchranker.Query, filter models.MemeFilter, offset, limit int) ([]searchranker.ScroredMeme, error) {
+	const batchSize = 100
+	res := []searchranker.ScroredMeme{}
+	for from := 0; len(res) < offset+limit; from += batchSize {
+		memes, err := load(ctx, from, batchSize)
 		if err != nil {
-			return nil, fmt.Errorf("can't list memes with offset %d: %w", listOffset, err)
+			return nil, fmt.Errorf("can't list memes with offset %d: %w", from, err)
+		}
+		for _, m := range searchranker.Filter(memes, query) {
+			if !filter.MatchMeme(m) {
+				continue
+			}
+			res = append(res, searchranker.ScroredMeme{Score: 0, Meme: m})
 		}
-		if len(nmemes) == 0 {
+		if len(memes) < batchSize {
 			break
 		}
-		listOffset += batchSize
-		memes = append(memes, nmemes...)
 	}
 
-	res, err := a.ranker.Rank(ctx, memes, req)
 	begin := min(offset, len(res))
 	end := min(offset+limit, len(res))
-
-	res = res[begin:end]
-
-	if err != nil {
-		return nil, fmt.Errorf("can't rank: %w", err)
-	}
-	return res, nil
+	return res[begin:end], nil
 }
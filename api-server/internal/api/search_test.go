@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"memesearch/internal/config"
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+	"memesearch/internal/storage"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// boardRepo serves boards from a map, other methods panic.
+type boardRepo struct {
+	models.BoardRepo
+	boards map[models.BoardID]models.Board
+}
+
+func (r boardRepo) GetBoardByID(ctx context.Context, id models.BoardID) (models.Board, error) {
+	b, ok := r.boards[id]
+	if !ok {
+		return models.Board{}, models.ErrBoardNotFound
+	}
+	return b, nil
+}
+
+func (r boardRepo) CreateBoard(ctx context.Context, owner models.UserID, name string) (models.Board, error) {
+	b := models.Board{ID: models.BoardID(name), Owner: owner, Name: name}
+	r.boards[b.ID] = b
+	return b, nil
+}
+
+// ListBoards returns boards owned by userID.
+func (r boardRepo) ListBoards(ctx context.Context, userID models.UserID, offset, limit int, sortBy string) ([]models.Board, error) {
+	res := []models.Board{}
+	for _, b := range r.boards {
+		if b.Owner == userID {
+			res = append(res, b)
+		}
+	}
+	slices.SortFunc(res, func(a, b models.Board) int { return strings.Compare(string(a.ID), string(b.ID)) })
+	return res[min(offset, len(res)):min(offset+limit, len(res))], nil
+}
+
+// searcher records requests and returns results.
+type searcher struct {
+	requests []searchranker.Request
+	results  []searchranker.ScroredMeme
+}
+
+func (s *searcher) Rank(ctx context.Context, memes []models.Meme, req searchranker.Request) ([]searchranker.ScroredMeme, error) {
+	panic("unexpected Rank")
+}
+
+func (s *searcher) Search(ctx context.Context, userID models.UserID, req searchranker.Request) ([]searchranker.ScroredMeme, error) {
+	s.requests = append(s.requests, req)
+	return s.results, nil
+}
+
+func testApi(s storage.Storage, ranker searchranker.Ranker) *api {
+	return newApi(s, config.SecretConfig{}, ranker, nil, searchranker.DefaultAnalyzer, config.SearchConfig{
+		Cursor: config.CursorConfig{Size: 10, TTL: time.Minute},
+	})
+}
+
+func TestSearchBoard(t *testing.T) {
+	boards := boardRepo{boards: map[models.BoardID]models.Board{"board": {ID: "board", Owner: "owner"}}}
+	meme := models.Meme{ID: "1", BoardID: "board", Description: map[string]string{"general": "грустный кот"}}
+	s := &searcher{results: []searchranker.ScroredMeme{{Score: 1, Meme: meme}}}
+	a := testApi(storage.Storage{BoardRepo: boards}, s)
+	ctx := context.WithValue(context.Background(), contextKey("user_id"), models.UserID("stranger"))
+
+	t.Run("Searcher scoped to board", func(t *testing.T) {
+		// the stranger isn't subscribed, visible boards must not be consulted
+		page, err := a.SearchBoard(ctx, "board", map[string]string{"general": "кот"}, models.MemeFilter{}, false, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, page.Memes, 1)
+		assert.Equal(t, meme.ID, page.Memes[0].Meme.ID)
+
+		require.Len(t, s.requests, 1)
+		assert.Equal(t, models.BoardID("board"), s.requests[0].Board)
+		assert.Equal(t, models.UserID("stranger"), s.requests[0].UserID)
+	})
+
+	t.Run("Unknown board", func(t *testing.T) {
+		_, err := a.SearchBoard(ctx, "missing", map[string]string{"general": "кот"}, models.MemeFilter{}, false, 0, 10)
+		assert.ErrorIs(t, err, ErrBoardNotFound)
+	})
+	t.Run("Cursor of board", func(t *testing.T) {
+		s.results = []searchranker.ScroredMeme{{Score: 2, Meme: meme}, {Score: 1, Meme: models.Meme{ID: "2", BoardID: "board"}}}
+
+		page, err := a.SearchBoard(ctx, "board", map[string]string{"general": "кот"}, models.MemeFilter{}, false, 0, 1)
+		require.NoError(t, err)
+		require.NotEmpty(t, page.NextCursor)
+
+		_, err = a.SearchNext(ctx, "", page.NextCursor, 1)
+		assert.ErrorIs(t, err, ErrInvalid{Param: "cursor"})
+		_, err = a.SearchNext(ctx, "other", page.NextCursor, 1)
+		assert.ErrorIs(t, err, ErrInvalid{Param: "cursor"})
+
+		next, err := a.SearchNext(ctx, "board", page.NextCursor, 1)
+		require.NoError(t, err)
+		require.Len(t, next.Memes, 1)
+		assert.Equal(t, models.MemeID("2"), next.Memes[0].Meme.ID)
+	})
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"fmt"
+	"memesearch/internal/config"
+	"memesearch/internal/lru"
+	"memesearch/internal/models"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// searchCache keeps ranked results of recent searches, so that repeated
+// queries such as inline queries typed letter by letter aren't ranked again.
+// Entries are dropped when memes visible to their user change or the user
+// sends a meme. Usage by other users reorders them only after they expire.
+type searchCache struct {
+	entries *lru.Cache[string, *searchSnapshot]
+	hits    atomic.Int64
+	misses  atomic.Int64
+}
+
+// CacheStats reports usage of the search cache.
+type CacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	Size   int   `json:"size"`
+}
+
+// newSearchCache returns nil which caches nothing when size is 0.
+func newSearchCache(cfg config.CacheConfig) *searchCache {
+	if cfg.Size <= 0 {
+		return nil
+	}
+	return &searchCache{entries: lru.New[string, *searchSnapshot](cfg.Size, cfg.TTL)}
+}
+
+func (c *searchCache) get(key string) (*searchSnapshot, bool) {
+	if c == nil {
+		return nil, false
+	}
+	snap, ok := c.entries.Get(key)
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return snap, ok
+}
+
+func (c *searchCache) add(key string, snap *searchSnapshot) {
+	if c == nil {
+		return
+	}
+	c.entries.Add(key, snap)
+}
+
+// invalidateBoards drops results ranked over any of boards.
+func (c *searchCache) invalidateBoards(boards ...models.BoardID) {
+	if c == nil {
+		return
+	}
+	c.entries.RemoveFunc(func(_ string, s *searchSnapshot) bool {
+		return slices.ContainsFunc(s.boards, func(b models.BoardID) bool { return slices.Contains(boards, b) })
+	})
+}
+
+// invalidateUser drops results of user.
+func (c *searchCache) invalidateUser(user models.UserID) {
+	if c == nil {
+		return
+	}
+	c.entries.RemoveFunc(func(_ string, s *searchSnapshot) bool { return s.userID == user })
+}
+
+func (c *searchCache) stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+	return CacheStats{Hits: c.hits.Load(), Misses: c.misses.Load(), Size: c.entries.Len()}
+}
+
+// searchKey identifies a search of user. Query fields are normalized so that
+// queries differing only in spacing share results.
+func searchKey(user models.UserID, query map[string]string, filter models.MemeFilter, explain bool) string {
+	fields := make([]string, 0, len(query))
+	for f, v := range query {
+		if v = strings.Join(strings.Fields(v), " "); v != "" {
+			fields = append(fields, f+"="+v)
+		}
+	}
+	slices.Sort(fields)
+
+	boards := slices.Clone(filter.Boards)
+	slices.Sort(boards)
+	return fmt.Sprintf("%s\x00%s\x00%v|%s|%d|%d|%s\x00%t", user, strings.Join(fields, "\x00"),
+		boards, filter.MediaType, filter.CreatedAfter.UnixNano(), filter.CreatedBefore.UnixNano(), filter.Owner, explain)
+}
+
+// SearchCacheStats reports hits and misses of the search cache.
+func (a *api) SearchCacheStats() CacheStats {
+	return a.cache.stats()
+}
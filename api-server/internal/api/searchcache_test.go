@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"memesearch/internal/config"
+	"memesearch/internal/models"
+	"memesearch/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchKey(t *testing.T) {
+	filter := models.MemeFilter{Boards: []models.BoardID{"b", "a"}}
+	key := searchKey("user", map[string]string{"general": "грустный  кот", "text": " подпись "}, filter, false)
+
+	t.Run("Normalized", func(t *testing.T) {
+		same := []string{
+			searchKey("user", map[string]string{"text": "подпись", "general": "грустный кот"}, filter, false),
+			searchKey("user", map[string]string{"general": " грустный кот\t", "text": "подпись", "tags": "  "}, filter, false),
+			searchKey("user", map[string]string{"general": "грустный кот", "text": "подпись"}, models.MemeFilter{Boards: []models.BoardID{"a", "b"}}, false),
+		}
+		for _, k := range same {
+			assert.Equal(t, key, k)
+		}
+	})
+
+	t.Run("Distinct", func(t *testing.T) {
+		query := map[string]string{"general": "грустный кот", "text": "подпись"}
+		other := []string{
+			searchKey("other", query, filter, false),
+			searchKey("user", query, filter, true),
+			searchKey("user", map[string]string{"general": "грустный кот подпись"}, filter, false),
+			searchKey("user", query, models.MemeFilter{Boards: []models.BoardID{"a"}}, false),
+			searchKey("user", query, models.MemeFilter{Boards: filter.Boards, MediaType: "video"}, false),
+			searchKey("user", query, models.MemeFilter{Boards: filter.Boards, Owner: "user"}, false),
+		}
+		for _, k := range other {
+			assert.NotEqual(t, key, k)
+		}
+	})
+}
+
+func TestSearchCacheInvalidate(t *testing.T) {
+	cache := newSearchCache(config.CacheConfig{Size: 10, TTL: time.Minute})
+	fill := func() {
+		cache.add("alice-a", &searchSnapshot{userID: "alice", boards: []models.BoardID{"a"}})
+		cache.add("alice-ab", &searchSnapshot{userID: "alice", boards: []models.BoardID{"a", "b"}})
+		cache.add("bob-b", &searchSnapshot{userID: "bob", boards: []models.BoardID{"b"}})
+		cache.add("bob-c", &searchSnapshot{userID: "bob", boards: []models.BoardID{"c"}})
+	}
+	cached := func() []string {
+		keys := []string{}
+		for _, k := range []string{"alice-a", "alice-ab", "bob-b", "bob-c"} {
+			if _, ok := cache.get(k); ok {
+				keys = append(keys, k)
+			}
+		}
+		return keys
+	}
+
+	t.Run("Boards", func(t *testing.T) {
+		fill()
+		cache.invalidateBoards("b", "d")
+		assert.Equal(t, []string{"alice-a", "bob-c"}, cached())
+	})
+
+	t.Run("User", func(t *testing.T) {
+		fill()
+		cache.invalidateUser("alice")
+		assert.Equal(t, []string{"bob-b", "bob-c"}, cached())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		cache := newSearchCache(config.CacheConfig{})
+		require.Nil(t, cache)
+		cache.add("alice-a", &searchSnapshot{userID: "alice"})
+		cache.invalidateBoards("a")
+		cache.invalidateUser("alice")
+		_, ok := cache.get("alice-a")
+		assert.False(t, ok)
+		assert.Equal(t, CacheStats{}, cache.stats())
+	})
+}
+
+func TestCreateBoardInvalidatesOwner(t *testing.T) {
+	boards := boardRepo{boards: map[models.BoardID]models.Board{}}
+	a := testApi(storage.Storage{BoardRepo: boards}, &searcher{})
+	a.cache = newSearchCache(config.CacheConfig{Size: 10, TTL: time.Minute})
+	a.cache.add("alice", &searchSnapshot{userID: "alice", boards: []models.BoardID{"a"}})
+	a.cache.add("bob", &searchSnapshot{userID: "bob", boards: []models.BoardID{"a"}})
+
+	ctx := context.WithValue(context.Background(), contextKey("user_id"), models.UserID("alice"))
+	_, err := a.CreateBoard(ctx, "new")
+	require.NoError(t, err)
+
+	_, ok := a.cache.get("alice")
+	assert.False(t, ok)
+	_, ok = a.cache.get("bob")
+	assert.True(t, ok)
+}
+
+func TestAddUsageInvalidatesUser(t *testing.T) {
+	a := testApi(storage.Storage{UsageRepo: usageRepo{}}, &searcher{})
+	a.cache = newSearchCache(config.CacheConfig{Size: 10, TTL: time.Minute})
+	a.cache.add("alice", &searchSnapshot{userID: "alice", boards: []models.BoardID{"a"}})
+	a.cache.add("bob", &searchSnapshot{userID: "bob", boards: []models.BoardID{"a"}})
+
+	ctx := context.WithValue(context.Background(), contextKey("user_id"), models.UserID("alice"))
+	require.NoError(t, a.AddUsage(ctx, "1", "inline"))
+
+	_, ok := a.cache.get("alice")
+	assert.False(t, ok)
+	_, ok = a.cache.get("bob")
+	assert.True(t, ok)
+}
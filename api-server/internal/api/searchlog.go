@@ -0,0 +1,163 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"memesearch/internal/models"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// logSearch records a search in the background, so that a slow log doesn't
+// slow searches down.
+func (a *api) logSearch(ctx context.Context, query map[string]string, filter models.MemeFilter, snap *searchSnapshot, page SearchPage, offset int, latency time.Duration) {
+	if !a.logCfg.Enabled {
+		return
+	}
+
+	e := models.SearchLogEntry{
+		UserID:    snap.userID,
+		Query:     normalizeQuery(query),
+		Filter:    filter,
+		Boards:    snap.boards,
+		Results:   len(snap.results),
+		Ranker:    a.rankerName,
+		Latency:   latency,
+		CreatedAt: time.Now(),
+	}
+	top := snap.results
+	if snap.list != nil {
+		// listed memes aren't ranked and only those up to the served page
+		// are known
+		e.Results, e.Ranker, top = offset+len(page.Memes), "", nil
+		if offset == 0 {
+			top = page.Memes
+		}
+		if e.Boards == nil {
+			var err error
+			e.Boards, err = a.visibleBoards(ctx, snap.userID, filter)
+			if err != nil {
+				slog.WarnContext(ctx, "Can't log search", "err", err)
+				return
+			}
+		}
+	}
+	for _, m := range top[:min(a.logCfg.TopResults, len(top))] {
+		e.TopIDs = append(e.TopIDs, m.Meme.ID)
+	}
+	if len(top) > 0 && snap.list == nil {
+		e.TopScore = top[0].Score
+		e.Ranker = cmp.Or(top[0].Ranker, a.rankerName)
+	}
+
+	a.searchLog.add(e)
+}
+
+// searchLogger writes logged searches one by one in the background, so that
+// a slow log neither slows searches down nor piles up writers. Searches are
+// dropped while the queue is full.
+type searchLogger struct {
+	repo    models.SearchLogRepo
+	entries chan models.SearchLogEntry
+	dropped atomic.Int64
+}
+
+// SearchLogStats reports usage of the search log queue.
+type SearchLogStats struct {
+	Queued  int   `json:"queued"`
+	Dropped int64 `json:"dropped"`
+}
+
+// newSearchLogger starts the writer of a queue of size entries.
+func newSearchLogger(repo models.SearchLogRepo, size int) *searchLogger {
+	l := &searchLogger{repo: repo, entries: make(chan models.SearchLogEntry, max(size, 1))}
+	go l.run()
+	return l
+}
+
+func (l *searchLogger) add(e models.SearchLogEntry) {
+	select {
+	case l.entries <- e:
+	default:
+		l.dropped.Add(1)
+	}
+}
+
+func (l *searchLogger) run() {
+	for e := range l.entries {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := l.repo.AddSearch(ctx, e); err != nil {
+			slog.WarnContext(ctx, "Can't log search", "user", e.UserID, "err", err)
+		}
+		cancel()
+	}
+}
+
+func (l *searchLogger) stats() SearchLogStats {
+	if l == nil {
+		return SearchLogStats{}
+	}
+	return SearchLogStats{Queued: len(l.entries), Dropped: l.dropped.Load()}
+}
+
+// SearchLogStats reports how many searches wait to be logged and how many
+// were dropped.
+func (a *api) SearchLogStats() SearchLogStats {
+	return a.searchLog.stats()
+}
+
+// normalizeQuery renders query fields in a stable order with lowercase
+// words separated by single spaces. The general field goes first without
+// a prefix.
+func normalizeQuery(query map[string]string) string {
+	parts := []string{}
+	fields := []string{}
+	for f, v := range query {
+		v = strings.Join(strings.Fields(strings.ToLower(v)), " ")
+		switch {
+		case v == "":
+		case f == "general":
+			parts = append(parts, v)
+		default:
+			fields = append(fields, f+":"+v)
+		}
+	}
+	slices.Sort(fields)
+	return strings.Join(append(parts, fields...), " ")
+}
+
+// FrequentQueries returns queries most often searched by users who see the board.
+func (a *api) FrequentQueries(ctx context.Context, board models.BoardID, since time.Time, limit int) ([]models.QueryStat, error) {
+	if _, err := a.GetBoardByID(ctx, board); err != nil {
+		return nil, fmt.Errorf("can't get board: %w", err)
+	}
+	stats, err := a.storage.FrequentQueries(ctx, board, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("can't get frequent queries: %w", err)
+	}
+	return stats, nil
+}
+
+// FailedQueries returns queries searched by users who see the board which
+// found nothing or scored below maxScore. The configured score is used when
+// maxScore is nil. Scores of different rankers aren't comparable, so only
+// scores of the current ranker are compared and during experiments only
+// searches without results are returned.
+func (a *api) FailedQueries(ctx context.Context, board models.BoardID, since time.Time, maxScore *float64, limit int) ([]models.QueryStat, error) {
+	if _, err := a.GetBoardByID(ctx, board); err != nil {
+		return nil, fmt.Errorf("can't get board: %w", err)
+	}
+	score := a.logCfg.LowScore
+	if maxScore != nil {
+		score = *maxScore
+	}
+	stats, err := a.storage.FailedQueries(ctx, board, since, a.rankerName, score, limit)
+	if err != nil {
+		return nil, fmt.Errorf("can't get failed queries: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"memesearch/internal/config"
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+	"memesearch/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := map[string]struct {
+		query map[string]string
+		want  string
+	}{
+		"empty":      {query: map[string]string{}, want: ""},
+		"blank":      {query: map[string]string{"general": "  ", "tags": ""}, want: ""},
+		"general":    {query: map[string]string{"general": "  Грустный\tКОТ "}, want: "грустный кот"},
+		"fields":     {query: map[string]string{"text": "Подпись", "general": "кот", "tags": "Пёс  Ёж"}, want: "кот tags:пёс ёж text:подпись"},
+		"no general": {query: map[string]string{"tags": "b", "source": "a"}, want: "source:a tags:b"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizeQuery(tt.query))
+		})
+	}
+}
+
+// searchLog sends added entries to a channel and records failed query requests.
+type searchLog struct {
+	models.SearchLogRepo
+	added    chan models.SearchLogEntry
+	rankers  []string
+	maxScore []float64
+}
+
+func (l *searchLog) AddSearch(ctx context.Context, e models.SearchLogEntry) error {
+	l.added <- e
+	return nil
+}
+
+func (l *searchLog) FailedQueries(ctx context.Context, board models.BoardID, since time.Time, ranker string, maxScore float64, limit int) ([]models.QueryStat, error) {
+	l.rankers = append(l.rankers, ranker)
+	l.maxScore = append(l.maxScore, maxScore)
+	return nil, nil
+}
+
+func TestSearchLogRanker(t *testing.T) {
+	boards := boardRepo{boards: map[models.BoardID]models.Board{"board": {ID: "board", Owner: "user"}}}
+	newApiWith := func(cfg config.SearchConfig) (*api, *searchLog) {
+		l := &searchLog{added: make(chan models.SearchLogEntry, 1)}
+		cfg.Log = config.SearchLogConfig{Enabled: true, TopResults: 5, LowScore: 0.2}
+		return newApi(storage.Storage{BoardRepo: boards, SearchLogRepo: l}, config.SecretConfig{}, &searcher{}, nil, searchranker.DefaultAnalyzer, cfg), l
+	}
+	snap := func(rankers ...string) *searchSnapshot {
+		s := &searchSnapshot{userID: "user", boards: []models.BoardID{"board"}}
+		for i, r := range rankers {
+			s.results = append(s.results, searchranker.ScroredMeme{Score: float64(len(rankers) - i), Ranker: r, Meme: models.Meme{ID: models.MemeID(r)}})
+		}
+		return s
+	}
+	query := map[string]string{"general": "Кот"}
+
+	t.Run("Configured ranker", func(t *testing.T) {
+		a, l := newApiWith(config.SearchConfig{Ranker: "bm25"})
+		a.logSearch(context.Background(), query, models.MemeFilter{}, snap(""), SearchPage{}, 0, time.Millisecond)
+		e := <-l.added
+		assert.Equal(t, "bm25", e.Ranker)
+		assert.Equal(t, "кот", e.Query)
+
+		_, err := a.FailedQueries(context.Background(), "board", time.Time{}, nil, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bm25"}, l.rankers)
+		assert.Equal(t, []float64{0.2}, l.maxScore)
+	})
+
+	t.Run("Experiment arms", func(t *testing.T) {
+		a, l := newApiWith(config.SearchConfig{Ranker: "default", Experiment: config.ExperimentConfig{Mode: "interleave"}})
+		a.logSearch(context.Background(), query, models.MemeFilter{}, snap("fts", "bm25"), SearchPage{}, 0, time.Millisecond)
+		assert.Equal(t, "fts", (<-l.added).Ranker)
+		a.logSearch(context.Background(), query, models.MemeFilter{}, snap(), SearchPage{}, 0, time.Millisecond)
+		assert.Empty(t, (<-l.added).Ranker)
+
+		// during experiments only searches without results fail
+		score := 0.5
+		_, err := a.FailedQueries(context.Background(), "board", time.Time{}, &score, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{""}, l.rankers)
+		assert.Equal(t, []float64{0.5}, l.maxScore)
+	})
+	t.Run("Without words", func(t *testing.T) {
+		a, l := newApiWith(config.SearchConfig{Ranker: "bm25"})
+		list := &searchSnapshot{userID: "user", list: func(ctx context.Context, offset, limit int) ([]searchranker.ScroredMeme, error) {
+			return nil, nil
+		}}
+		page := SearchPage{Memes: []searchranker.ScroredMeme{{Meme: models.Meme{ID: "1"}}, {Meme: models.Meme{ID: "2"}}}}
+		a.logSearch(context.Background(), map[string]string{}, models.MemeFilter{MediaType: models.MediaTypeVideo}, list, page, 0, time.Millisecond)
+		e := <-l.added
+		assert.Equal(t, []models.BoardID{"board"}, e.Boards)
+		assert.Equal(t, 2, e.Results)
+		assert.Equal(t, []models.MemeID{"1", "2"}, e.TopIDs)
+		// unranked searches aren't compared by score
+		assert.Empty(t, e.Ranker)
+
+		a.logSearch(context.Background(), map[string]string{}, models.MemeFilter{}, list, page, 10, time.Millisecond)
+		e = <-l.added
+		assert.Equal(t, 12, e.Results)
+		assert.Empty(t, e.TopIDs)
+	})
+}
+
+// blockingLog blocks writes until release is closed.
+type blockingLog struct {
+	models.SearchLogRepo
+	started chan struct{}
+	release chan struct{}
+}
+
+func (l *blockingLog) AddSearch(ctx context.Context, e models.SearchLogEntry) error {
+	l.started <- struct{}{}
+	<-l.release
+	return nil
+}
+
+func TestSearchLoggerDrops(t *testing.T) {
+	repo := &blockingLog{started: make(chan struct{}, 10), release: make(chan struct{})}
+	defer close(repo.release)
+	l := newSearchLogger(repo, 2)
+
+	l.add(models.SearchLogEntry{Query: "1"})
+	<-repo.started
+	// the writer is busy with the first search, two more wait
+	for _, q := range []string{"2", "3", "4", "5"} {
+		l.add(models.SearchLogEntry{Query: q})
+	}
+	assert.Equal(t, SearchLogStats{Queued: 2, Dropped: 2}, l.stats())
+}
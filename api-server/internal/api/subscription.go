@@ -11,6 +11,7 @@ func (a *api) Subscribe(ctx context.Context, user models.UserID, board models.Bo
 	if err != nil {
 		return fmt.Errorf("can't subscribe: %w", err)
 	}
+	a.cache.invalidateUser(user)
 
 	return nil
 }
@@ -23,5 +24,19 @@ func (a *api) Unsubscribe(ctx context.Context, user models.UserID, board models.
 		}
 		return fmt.Errorf("can't unsubscribe: %w", err)
 	}
+	a.cache.invalidateUser(user)
+	return nil
+}
+
+// SetSubscriptionWeight sets how much memes of the board weigh in search results of the user.
+func (a *api) SetSubscriptionWeight(ctx context.Context, user models.UserID, board models.BoardID, weight float64) error {
+	err := a.storage.SetSubscriptionWeight(ctx, user, board, weight)
+	if err != nil {
+		if err == models.ErrSubNotFound {
+			return ErrSubNotFound
+		}
+		return fmt.Errorf("can't set weight: %w", err)
+	}
+	a.cache.invalidateUser(user)
 	return nil
 }
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+	"slices"
+	"strings"
+)
+
+// Suggestions are search queries suggested for a typed prefix.
+type Suggestions struct {
+	// Completions are prefix with its last word completed.
+	Completions []string
+	// DidYouMean is prefix with misspelled words corrected. It is set
+	// only when no word of prefix is found on visible boards.
+	DidYouMean string
+}
+
+// Suggest completes the last word of prefix with description words of visible
+// memes. Words of frequent memes come first, of frequently used ones too when
+// popularity is enabled.
+func (a *api) Suggest(ctx context.Context, prefix string, limit int) (Suggestions, error) {
+	logger := slog.Default().With("from", "api.Suggest")
+	logger.InfoContext(ctx, "Started")
+
+	res := Suggestions{Completions: []string{}}
+	if a.index == nil {
+		// rankers searching the database keep no vocabulary
+		return res, nil
+	}
+
+	userID := GetUserID(ctx)
+	if userID == "" {
+		userID = "guest"
+	}
+	boards, err := a.visibleBoards(ctx, userID, models.MemeFilter{})
+	if err != nil {
+		return Suggestions{}, fmt.Errorf("can't get visible boards: %w", err)
+	}
+
+	i := strings.LastIndex(prefix, " ")
+	head, last := prefix[:i+1], prefix[i+1:]
+	if w, ok := plainWord(last); ok {
+		words, err := a.complete(ctx, userID, boards, w, limit)
+		if err != nil {
+			return Suggestions{}, fmt.Errorf("can't complete: %w", err)
+		}
+		for _, w := range words {
+			res.Completions = append(res.Completions, head+w)
+		}
+	}
+
+	if !a.known(boards, prefix) {
+		res.DidYouMean = a.correct(boards, prefix)
+	}
+	return res, nil
+}
+
+// known reports whether any word of query is a word of memes on boards. The
+// last word may be a prefix of such a word as it is still being typed.
+func (a *api) known(boards []models.BoardID, query string) bool {
+	tokens := strings.Fields(query)
+	for i, t := range tokens {
+		w, ok := plainWord(t)
+		if !ok {
+			continue
+		}
+		if a.index.Contains(boards, w) {
+			return true
+		}
+		typing := i == len(tokens)-1 && !strings.HasSuffix(query, " ")
+		if typing && len(a.index.Complete(boards, w, 1)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// complete returns up to limit visible words starting with prefix ordered by
+// the number of memes containing them boosted by usage of these memes when
+// popularity is enabled.
+func (a *api) complete(ctx context.Context, userID models.UserID, boards []models.BoardID, prefix string, limit int) ([]string, error) {
+	// usage reorders frequent words, so a few more of them are considered
+	cs := a.index.Complete(boards, prefix, 3*limit)
+	ids := []models.MemeID{}
+	for _, c := range cs {
+		ids = append(ids, c.Memes...)
+	}
+	w := a.popularity
+	counts := map[models.MemeID]models.UsageCount{}
+	if w != (searchranker.PopularityWeights{}) {
+		var err error
+		counts, err = a.storage.UsageCounts(ctx, ids, userID)
+		if err != nil {
+			return nil, fmt.Errorf("can't get usage counts: %w", err)
+		}
+	}
+
+	type weighted struct {
+		word   string
+		weight float64
+	}
+	ws := make([]weighted, 0, len(cs))
+	for _, c := range cs {
+		global, user := 0, 0
+		for _, id := range c.Memes {
+			global += counts[id].Global
+			user += counts[id].User
+		}
+		boost := 1 + w.Global*math.Log1p(float64(global)) + w.User*math.Log1p(float64(user))
+		ws = append(ws, weighted{word: c.Word, weight: float64(c.Freq) * boost})
+	}
+	slices.SortStableFunc(ws, func(a, b weighted) int {
+		return cmp.Compare(b.weight, a.weight)
+	})
+
+	res := make([]string, 0, limit)
+	for _, w := range ws[:min(limit, len(ws))] {
+		res = append(res, w.word)
+	}
+	return res, nil
+}
+
+// correct replaces words of query missing on boards with the most similar
+// words found there. It returns an empty string when nothing is corrected.
+func (a *api) correct(boards []models.BoardID, query string) string {
+	tokens := strings.Fields(query)
+	changed := false
+	for i, t := range tokens {
+		w, ok := plainWord(t)
+		if !ok {
+			continue
+		}
+		if c, ok := a.index.Correct(boards, w); ok && c != w {
+			tokens[i] = c
+			changed = true
+		}
+	}
+	if !changed {
+		return ""
+	}
+	return strings.Join(tokens, " ")
+}
+
+// plainWord returns the normalized token when it is a single word without
+// query syntax like -negation or field: prefixes.
+func plainWord(token string) (string, bool) {
+	ws := searchranker.Words(token)
+	if len(ws) != 1 || len([]rune(ws[0])) != len([]rune(token)) {
+		return "", false
+	}
+	return ws[0], true
+}
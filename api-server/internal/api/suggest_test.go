@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"memesearch/internal/config"
+	"memesearch/internal/models"
+	"memesearch/internal/searchindex"
+	"memesearch/internal/searchranker"
+	"memesearch/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// usageRepo returns fixed usage counts and drops usage events.
+type usageRepo struct {
+	models.UsageRepo
+	counts map[models.MemeID]models.UsageCount
+}
+
+func (r usageRepo) AddUsage(ctx context.Context, e models.UsageEvent) error {
+	return nil
+}
+
+func (r usageRepo) UsageCounts(ctx context.Context, memes []models.MemeID, user models.UserID) (map[models.MemeID]models.UsageCount, error) {
+	return r.counts, nil
+}
+
+func TestSuggest(t *testing.T) {
+	boards := boardRepo{boards: map[models.BoardID]models.Board{"board": {ID: "board", Owner: "alice"}}}
+	index := searchindex.New(searchranker.SimpleAnalyzer{})
+	index.Add(models.Meme{ID: "1", BoardID: "board", Description: map[string]string{"general": "грустный кот"}})
+	index.Add(models.Meme{ID: "2", BoardID: "board", Description: map[string]string{"general": "котлета"}})
+	index.Add(models.Meme{ID: "3", BoardID: "board", Description: map[string]string{"general": "котлета"}})
+	// the ranker panics, suggestions are made from the index only
+	s := &searcher{}
+	a := newApi(storage.Storage{BoardRepo: boards, UsageRepo: usageRepo{}}, config.SecretConfig{}, s, index, searchranker.SimpleAnalyzer{}, config.SearchConfig{})
+	ctx := context.WithValue(context.Background(), contextKey("user_id"), models.UserID("alice"))
+
+	t.Run("Completions", func(t *testing.T) {
+		res, err := a.Suggest(ctx, "грустный ко", 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"грустный котлета", "грустный кот"}, res.Completions)
+		assert.Empty(t, res.DidYouMean)
+	})
+
+	t.Run("Popularity", func(t *testing.T) {
+		usage := usageRepo{counts: map[models.MemeID]models.UsageCount{"1": {Global: 100, User: 20}}}
+		a := newApi(storage.Storage{BoardRepo: boards, UsageRepo: usage}, config.SecretConfig{}, s, index, searchranker.SimpleAnalyzer{}, config.SearchConfig{
+			Popularity: config.PopularityConfig{Enabled: true, GlobalWeight: 0.1, UserWeight: 0.3},
+		})
+		res, err := a.Suggest(ctx, "ко", 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"кот", "котлета"}, res.Completions)
+	})
+
+	t.Run("Did you mean", func(t *testing.T) {
+		res, err := a.Suggest(ctx, "грустеый кор", 2)
+		require.NoError(t, err)
+		assert.Equal(t, "грустный кот", res.DidYouMean)
+
+		res, err = a.Suggest(ctx, "грустеый котл", 2)
+		require.NoError(t, err)
+		assert.Empty(t, res.DidYouMean)
+	})
+
+	assert.Empty(t, s.requests)
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"memesearch/internal/models"
+	"time"
+)
+
+// AddUsage records that the user sent the meme from source. Cached results
+// of the user are dropped as their popularity boosts change, results of
+// other users keep global usage counts until they expire.
+func (a *api) AddUsage(ctx context.Context, id models.MemeID, source string) error {
+	logger := slog.Default().With("from", "api.AddUsage")
+	logger.InfoContext(ctx, "Started", "id", id, "source", source)
+
+	userID := GetUserID(ctx)
+	if userID == "" {
+		userID = "guest"
+	}
+	err := a.storage.AddUsage(ctx, models.UsageEvent{
+		MemeID:    id,
+		UserID:    userID,
+		Source:    source,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("can't add usage: %w", err)
+	}
+	a.cache.invalidateUser(userID)
+	return nil
+}
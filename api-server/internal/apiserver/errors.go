@@ -30,6 +30,7 @@ func ErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
 
 	var resultErr Error
 	var invalidParam *InvalidParamFormatError
+	var duplicate api.ErrDuplicate
 
 	switch {
 	case errors.Is(err, api.ErrMediaNotFound),
@@ -59,6 +60,18 @@ func ErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
 		w.WriteHeader(http.StatusConflict)
 		w.Write([]byte(unwrapErr(err).Error()))
 		return
+	case errors.As(err, &duplicate):
+		b := map[string]any{"memeId": string(duplicate.Meme), "exact": duplicate.Exact}
+		resultErr = Error{Id: "DUPLICATE_MEDIA", Message: duplicate.Error(), Body: &b}
+		w.Header().Add("Content-type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		body, err := json.MarshalIndent(resultErr, "", " ")
+		if err != nil {
+			slog.ErrorContext(ctx, "Can't marshall json", "err", err)
+		}
+		w.Write(body)
+		return
+
 	case errors.Is(err, api.ErrInvalidToken):
 		w.WriteHeader(http.StatusUnauthorized)
 		w.Write([]byte("Invalid token"))
@@ -37,10 +37,43 @@ func convertMemeToServer(m models.Meme) Meme {
 }
 
 func convertScoredMemeToServer(m searchranker.ScroredMeme) ScoredMeme {
-	return ScoredMeme{
+	res := ScoredMeme{
 		Score: float64(int(m.Score*100)) / 100,
 		Meme:  convertMemeToServer(m.Meme),
 	}
+	if len(m.Corrections) > 0 {
+		res.Corrections = &m.Corrections
+	}
+	if m.Explain != nil {
+		explain := make([]TermMatch, 0, len(m.Explain))
+		for _, tm := range m.Explain {
+			explain = append(explain, convertTermMatchToServer(tm))
+		}
+		res.Explain = &explain
+	}
+	return res
+}
+
+func convertTermMatchToServer(tm searchranker.TermMatch) TermMatch {
+	res := TermMatch{
+		Term:       tm.Term,
+		Similarity: ptr(tm.Similarity),
+		Threshold:  ptr(tm.Threshold),
+		Weight:     ptr(tm.Weight),
+		Score:      tm.Score,
+		Used:       tm.Used,
+	}
+	if tm.Variant != "" {
+		res.Variant = ptr(tm.Variant)
+	}
+	if tm.Word != "" {
+		res.Field = ptr(tm.Field)
+		res.Word = ptr(tm.Word)
+	}
+	if tm.IDF != 0 {
+		res.Idf = ptr(tm.IDF)
+	}
+	return res
 }
 
 func convertMapToString(m map[string]any) (map[string]string, error) {
@@ -64,11 +97,23 @@ func convertMapToAny(m map[string]string) map[string]any {
 }
 
 func convertBoardToServer(m models.Board) Board {
-	return Board{
+	b := Board{
 		Id:    string(m.ID),
 		Owner: string(m.Owner),
 		Name:  m.Name,
 	}
+	if len(m.Settings.Synonyms) > 0 {
+		b.Settings = &BoardSettings{Synonyms: &m.Settings.Synonyms}
+	}
+	return b
+}
+
+// nonNil returns an empty slice instead of nil, so that it is encoded as [].
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
 }
 
 func convertBoardListToServer(ms []models.Board) []Board {
@@ -79,6 +124,20 @@ func convertBoardListToServer(ms []models.Board) []Board {
 	return res
 }
 
+func convertQueryStatsToServer(stats []models.QueryStat) QueryStats {
+	res := QueryStats{Items: make([]QueryStat, 0, len(stats))}
+	for _, st := range stats {
+		res.Items = append(res.Items, QueryStat{
+			Query:          st.Query,
+			Searches:       st.Searches,
+			Users:          st.Users,
+			AvgResults:     st.AvgResults,
+			LastSearchedAt: st.LastSearchedAt,
+		})
+	}
+	return res
+}
+
 func invalidInput(parametr, message string, args ...any) error {
 	return &InvalidParamFormatError{ParamName: parametr, Err: fmt.Errorf(message, args...)}
 }
@@ -86,3 +145,11 @@ func invalidInput(parametr, message string, args ...any) error {
 func ptr[T any](r T) *T {
 	return &r
 }
+
+// optional returns nil for empty s, so that it is omitted from responses.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -87,12 +87,12 @@ func (s ServerImpl) GetMemeByID(ctx context.Context, request GetMemeByIDRequestO
 
 // ListMemes implements StrictServerInterface.
 func (s ServerImpl) ListMemes(ctx context.Context, request ListMemesRequestObject) (ListMemesResponseObject, error) {
-	offset, limit, sortBy, err := request.GetParams()
+	offset, limit, sortBy, filter, err := request.GetParams()
 	if err != nil {
 		return nil, fmt.Errorf("can't get params: %w", err)
 	}
 
-	memes, err := s.api.ListMemes(ctx, offset, limit, sortBy)
+	memes, err := s.api.ListMemes(ctx, filter, offset, limit, sortBy)
 	if err != nil {
 		return nil, fmt.Errorf("can't list memes: %w", err)
 	}
@@ -105,6 +105,26 @@ func (s ServerImpl) ListMemes(ctx context.Context, request ListMemesRequestObjec
 	return ListMemes200JSONResponse{Items: conv}, nil
 }
 
+// ListBoardMemes implements StrictServerInterface.
+func (s ServerImpl) ListBoardMemes(ctx context.Context, request ListBoardMemesRequestObject) (ListBoardMemesResponseObject, error) {
+	board, offset, limit, err := request.GetParams()
+	if err != nil {
+		return nil, fmt.Errorf("can't get params: %w", err)
+	}
+
+	memes, err := s.api.ListBoardMemes(ctx, board, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("can't list memes: %w", err)
+	}
+
+	conv := make([]Meme, 0, len(memes))
+	for _, m := range memes {
+		conv = append(conv, convertMemeToServer(m))
+	}
+
+	return ListBoardMemes200JSONResponse{Items: conv}, nil
+}
+
 // PostMeme implements StrictServerInterface.
 func (s ServerImpl) PostMeme(ctx context.Context, request PostMemeRequestObject) (PostMemeResponseObject, error) {
 	board, filename, dsc, err := request.GetParams()
@@ -120,40 +140,74 @@ func (s ServerImpl) PostMeme(ctx context.Context, request PostMemeRequestObject)
 	return PostMeme200JSONResponse(convertMemeToServer(meme)), nil
 }
 
+// PostMemeUsage implements StrictServerInterface.
+func (s ServerImpl) PostMemeUsage(ctx context.Context, request PostMemeUsageRequestObject) (PostMemeUsageResponseObject, error) {
+	id, source, err := request.GetParams()
+	if err != nil {
+		return nil, fmt.Errorf("can't get params: %w", err)
+	}
+
+	err = s.api.AddUsage(ctx, id, source)
+	if err != nil {
+		return nil, fmt.Errorf("can't add usage: %w", err)
+	}
+
+	return PostMemeUsage200Response{}, nil
+}
+
 // PutMediaByID implements StrictServerInterface.
 func (s ServerImpl) PutMediaByID(ctx context.Context, request PutMediaByIDRequestObject) (PutMediaByIDResponseObject, error) {
-	// TODO prettyfiy put media
-	form, err := request.Body.ReadForm(20 * 1024 * 1024)
+	filename, data, err := readMedia(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	force := request.Params.Force != nil && *request.Params.Force
+	err = s.api.SetMedia(ctx, models.Media{ID: models.MediaID(request.MediaID), Body: data}, force)
+	if err != nil {
+		return nil, fmt.Errorf("can't set media: %w", err)
+	}
+	_, err = s.api.UpdateMeme(ctx, models.MemeID(request.MediaID), nil, &filename, nil)
+	if err != nil {
+		return nil, fmt.Errorf("can't set filename: %w", err)
+	}
+
+	return PutMediaByID200Response{}, nil
+}
+
+// readMedia reads the validated media file of a multipart form.
+func readMedia(body *multipart.Reader) (filename string, data []byte, err error) {
+	form, err := body.ReadForm(20 * 1024 * 1024)
 	if err != nil {
 		if err == multipart.ErrMessageTooLarge {
-			return nil, invalidInput("body", "body is too big")
+			return "", nil, invalidInput("body", "body is too big")
 		}
-		return nil, invalidInput("form", "%s", err.Error())
+		return "", nil, invalidInput("form", "%s", err.Error())
 	}
 
 	files, ok := form.File["media"]
 	if !ok || len(files) == 0 {
-		return nil, &InvalidParamFormatError{ParamName: "form data", Err: fmt.Errorf("no file provided")}
+		return "", nil, &InvalidParamFormatError{ParamName: "form data", Err: fmt.Errorf("no file provided")}
 	}
 
 	fileHeader := files[0]
-	filename := fileHeader.Filename
+	filename = fileHeader.Filename
 	file, err := fileHeader.Open()
 	if err != nil {
-		return nil, fmt.Errorf("can't open file: %w", err)
+		return "", nil, fmt.Errorf("can't open file: %w", err)
 	}
 	defer file.Close()
 
 	const maxFileSize = 16 * 1024 * 1024
 	if fileHeader.Size > maxFileSize {
-		return nil, &InvalidParamFormatError{ParamName: "form file", Err: fmt.Errorf("file size exceed maximum size")}
+		return "", nil, &InvalidParamFormatError{ParamName: "form file", Err: fmt.Errorf("file size exceed maximum size")}
 	}
 
 	// 3. Validate media type
 	buffer := make([]byte, 512)
 	_, err = file.Read(buffer)
 	if err != nil {
-		return nil, fmt.Errorf("can't read buffer prefix: %w", err)
+		return "", nil, fmt.Errorf("can't read buffer prefix: %w", err)
 	}
 
 	contentType := http.DetectContentType(buffer)
@@ -164,30 +218,20 @@ func (s ServerImpl) PutMediaByID(ctx context.Context, request PutMediaByIDReques
 	}
 
 	if !allowedTypes[contentType] {
-		return nil, invalidInput("media", "bad media type")
+		return "", nil, invalidInput("media", "bad media type")
 	}
 
 	// Reset file pointer after reading the header
 	_, err = file.Seek(0, 0)
 	if err != nil {
-		return nil, fmt.Errorf("can't seek file: %w", err)
+		return "", nil, fmt.Errorf("can't seek file: %w", err)
 	}
 
-	data, err := io.ReadAll(file)
-	if err != nil {
-		return nil, fmt.Errorf("can't read file: %w", err)
-	}
-
-	err = s.api.SetMedia(ctx, models.Media{ID: models.MediaID(request.MediaID), Body: data})
-	if err != nil {
-		return nil, fmt.Errorf("can't set media: %w", err)
-	}
-	_, err = s.api.UpdateMeme(ctx, models.MemeID(request.MediaID), nil, &filename, nil)
+	data, err = io.ReadAll(file)
 	if err != nil {
-		return nil, fmt.Errorf("can't set filename: %w", err)
+		return "", nil, fmt.Errorf("can't read file: %w", err)
 	}
-
-	return PutMediaByID200Response{}, nil
+	return filename, data, nil
 }
 
 // UpdateMemeByID implements StrictServerInterface.
@@ -207,23 +251,124 @@ func (s ServerImpl) UpdateMemeByID(ctx context.Context, request UpdateMemeByIDRe
 
 // SearchByBoardID implements StrictServerInterface.
 func (s ServerImpl) SearchMemes(ctx context.Context, request SearchMemesRequestObject) (SearchMemesResponseObject, error) {
-	offset, limit, dsc, err := request.GetParams()
+	offset, limit, dsc, filter, explain, cursor, err := request.GetParams()
 	if err != nil {
 		return nil, fmt.Errorf("can't get params: %w", err)
 	}
 
-	memes, err := s.api.Search(ctx, dsc, offset, limit)
+	var page api.SearchPage
+	if cursor != "" {
+		page, err = s.api.SearchNext(ctx, "", cursor, limit)
+	} else {
+		page, err = s.api.Search(ctx, dsc, filter, explain, offset, limit)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("can't search: %w", err)
 	}
 
+	conv := make([]ScoredMeme, 0, len(page.Memes))
+	for _, m := range page.Memes {
+		conv = append(conv, convertScoredMemeToServer(m))
+	}
+
+	return SearchMemes200JSONResponse{
+		Items:      conv,
+		Page:       page.Page,
+		PageSize:   page.PageSize,
+		NextCursor: optional(page.NextCursor),
+	}, nil
+}
+
+// SearchBoardMemes implements StrictServerInterface.
+func (s ServerImpl) SearchBoardMemes(ctx context.Context, request SearchBoardMemesRequestObject) (SearchBoardMemesResponseObject, error) {
+	board, offset, limit, dsc, filter, explain, cursor, err := request.GetParams()
+	if err != nil {
+		return nil, fmt.Errorf("can't get params: %w", err)
+	}
+
+	var page api.SearchPage
+	if cursor != "" {
+		page, err = s.api.SearchNext(ctx, board, cursor, limit)
+	} else {
+		page, err = s.api.SearchBoard(ctx, board, dsc, filter, explain, offset, limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't search: %w", err)
+	}
+
+	conv := make([]ScoredMeme, 0, len(page.Memes))
+	for _, m := range page.Memes {
+		conv = append(conv, convertScoredMemeToServer(m))
+	}
+
+	return SearchBoardMemes200JSONResponse{
+		Items:      conv,
+		Page:       page.Page,
+		PageSize:   page.PageSize,
+		NextCursor: optional(page.NextCursor),
+	}, nil
+}
+
+// SearchMemesByImage implements StrictServerInterface.
+func (s ServerImpl) SearchMemesByImage(ctx context.Context, request SearchMemesByImageRequestObject) (SearchMemesByImageResponseObject, error) {
+	offset, limit, boards, maxDistance, err := request.GetParams()
+	if err != nil {
+		return nil, fmt.Errorf("can't get params: %w", err)
+	}
+	_, data, err := readMedia(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	memes, err := s.api.SearchByImage(ctx, data, boards, maxDistance, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("can't search by image: %w", err)
+	}
+
 	conv := make([]ScoredMeme, 0, len(memes))
 	for _, m := range memes {
 		conv = append(conv, convertScoredMemeToServer(m))
 	}
 
-	return SearchMemes200JSONResponse{Items: conv}, nil
+	return SearchMemesByImage200JSONResponse{Items: conv, Page: offset / limit, PageSize: limit}, nil
+}
+
+// SuggestQueries implements StrictServerInterface.
+func (s ServerImpl) SuggestQueries(ctx context.Context, request SuggestQueriesRequestObject) (SuggestQueriesResponseObject, error) {
+	prefix, limit, err := request.GetParams()
+	if err != nil {
+		return nil, fmt.Errorf("can't get params: %w", err)
+	}
+
+	sug, err := s.api.Suggest(ctx, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("can't suggest: %w", err)
+	}
+
+	res := SuggestQueries200JSONResponse{Completions: sug.Completions}
+	if sug.DidYouMean != "" {
+		res.DidYouMean = &sug.DidYouMean
+	}
+	return res, nil
+}
+
+// SuggestDescription implements StrictServerInterface.
+func (s ServerImpl) SuggestDescription(ctx context.Context, request SuggestDescriptionRequestObject) (SuggestDescriptionResponseObject, error) {
+	id, limit, err := request.GetParams()
+	if err != nil {
+		return nil, fmt.Errorf("can't get params: %w", err)
+	}
+
+	sugs, err := s.api.SuggestDescription(ctx, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("can't suggest description: %w", err)
+	}
 
+	res := SuggestDescription200JSONResponse{Items: make([]DescriptionSuggestion, 0, len(sugs))}
+	for _, sug := range sugs {
+		res.Items = append(res.Items, DescriptionSuggestion{Word: sug.Word, Source: DescriptionSuggestionSource(sug.Source)})
+	}
+	return res, nil
 }
 
 // AuthLogin implements StrictServerInterface.
@@ -305,6 +450,63 @@ func (s ServerImpl) GetBoardByID(ctx context.Context, request GetBoardByIDReques
 	return GetBoardByID200JSONResponse(convertBoardToServer(board)), nil
 }
 
+// GetBoardSynonyms implements StrictServerInterface.
+func (s ServerImpl) GetBoardSynonyms(ctx context.Context, request GetBoardSynonymsRequestObject) (GetBoardSynonymsResponseObject, error) {
+	id := models.BoardID(request.BoardID)
+
+	groups, err := s.api.GetBoardSynonyms(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("can't get synonyms: %w", err)
+	}
+
+	return GetBoardSynonyms200JSONResponse{Groups: nonNil(groups)}, nil
+}
+
+// GetFrequentQueries implements StrictServerInterface.
+func (s ServerImpl) GetFrequentQueries(ctx context.Context, request GetFrequentQueriesRequestObject) (GetFrequentQueriesResponseObject, error) {
+	board, since, limit, err := request.GetParams()
+	if err != nil {
+		return nil, fmt.Errorf("can't get params: %w", err)
+	}
+
+	stats, err := s.api.FrequentQueries(ctx, board, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("can't get frequent queries: %w", err)
+	}
+
+	return GetFrequentQueries200JSONResponse(convertQueryStatsToServer(stats)), nil
+}
+
+// GetFailedQueries implements StrictServerInterface.
+func (s ServerImpl) GetFailedQueries(ctx context.Context, request GetFailedQueriesRequestObject) (GetFailedQueriesResponseObject, error) {
+	board, since, maxScore, limit, err := request.GetParams()
+	if err != nil {
+		return nil, fmt.Errorf("can't get params: %w", err)
+	}
+
+	stats, err := s.api.FailedQueries(ctx, board, since, maxScore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("can't get failed queries: %w", err)
+	}
+
+	return GetFailedQueries200JSONResponse(convertQueryStatsToServer(stats)), nil
+}
+
+// SetBoardSynonyms implements StrictServerInterface.
+func (s ServerImpl) SetBoardSynonyms(ctx context.Context, request SetBoardSynonymsRequestObject) (SetBoardSynonymsResponseObject, error) {
+	id, groups, err := request.GetParams()
+	if err != nil {
+		return nil, fmt.Errorf("can't get params: %w", err)
+	}
+
+	groups, err = s.api.SetBoardSynonyms(ctx, id, groups)
+	if err != nil {
+		return nil, fmt.Errorf("can't set synonyms: %w", err)
+	}
+
+	return SetBoardSynonyms200JSONResponse{Groups: nonNil(groups)}, nil
+}
+
 // PostBoard implements StrictServerInterface.
 func (s ServerImpl) PostBoard(ctx context.Context, request PostBoardRequestObject) (PostBoardResponseObject, error) {
 	name, err := request.GetParams()
@@ -361,6 +563,21 @@ func (s ServerImpl) SubscribeByBoardID(ctx context.Context, request SubscribeByB
 	return SubscribeByBoardID200Response{}, nil
 }
 
+// SetSubscriptionWeight implements StrictServerInterface.
+func (s ServerImpl) SetSubscriptionWeight(ctx context.Context, request SetSubscriptionWeightRequestObject) (SetSubscriptionWeightResponseObject, error) {
+	boardID, weight, err := request.GetParams()
+	if err != nil {
+		return nil, fmt.Errorf("can't get params: %w", err)
+	}
+
+	err = s.api.SetSubscriptionWeight(ctx, boardID, weight)
+	if err != nil {
+		return nil, fmt.Errorf("can't set weight: %w", err)
+	}
+
+	return SetSubscriptionWeight200Response{}, nil
+}
+
 // UnsubscribeByBoardID implements StrictServerInterface.
 func (s ServerImpl) UnsubscribeByBoardID(ctx context.Context, request UnsubscribeByBoardIDRequestObject) (UnsubscribeByBoardIDResponseObject, error) {
 	boardID := models.BoardID(request.BoardID)
@@ -5,6 +5,8 @@ import (
 	"memesearch/internal/models"
 	"regexp"
 	"slices"
+	"strings"
+	"time"
 )
 
 func (r UpdateMemeByIDRequestObject) GetParams() (
@@ -46,7 +48,7 @@ var (
 )
 
 func (r SearchMemesRequestObject) GetParams() (
-	offset, limit int, dsc map[string]string, err error) {
+	offset, limit int, dsc map[string]string, filter models.MemeFilter, explain bool, cursor string, err error) {
 	offset = DefaultOffset
 	limit = DefaultLimit
 
@@ -66,12 +68,84 @@ func (r SearchMemesRequestObject) GetParams() (
 		return
 	}
 
-	dsc = getDescriptionMap(r.Params)
+	filter, err = getMemeFilter(r.Params.BoardId, r.Params.MediaType, r.Params.CreatedAfter, r.Params.CreatedBefore, r.Params.Owner)
+	if err != nil {
+		return
+	}
+
+	dsc = getDescriptionMap(r.Params.General, r.Params.Text, r.Params.Tags, r.Params.Source)
+	if r.Params.Explain != nil {
+		explain = *r.Params.Explain
+	}
+	if r.Params.Cursor != nil {
+		cursor = *r.Params.Cursor
+	}
+	return
+}
+
+func (r SearchBoardMemesRequestObject) GetParams() (
+	board models.BoardID, offset, limit int, dsc map[string]string, filter models.MemeFilter, explain bool, cursor string, err error) {
+	board = models.BoardID(r.BoardID)
+	offset = DefaultOffset
+	limit = DefaultLimit
+
+	if r.Params.Offset != nil {
+		offset = *r.Params.Offset
+	}
+	if offset < 0 {
+		err = invalidInput("offset", "must be offset>=0")
+		return
+	}
+
+	if r.Params.Limit != nil {
+		limit = *r.Params.Limit
+	}
+	if limit < 1 || limit > 100 {
+		err = invalidInput("limit", "must be 1 <= limit <= 100")
+		return
+	}
+
+	filter, err = getMemeFilter(nil, r.Params.MediaType, r.Params.CreatedAfter, r.Params.CreatedBefore, nil)
+	if err != nil {
+		return
+	}
+
+	dsc = getDescriptionMap(r.Params.General, r.Params.Text, r.Params.Tags, r.Params.Source)
+	if r.Params.Explain != nil {
+		explain = *r.Params.Explain
+	}
+	if r.Params.Cursor != nil {
+		cursor = *r.Params.Cursor
+	}
+	return
+}
+
+func (r ListBoardMemesRequestObject) GetParams() (
+	board models.BoardID, offset, limit int, err error) {
+	board = models.BoardID(r.BoardID)
+	offset = DefaultOffset
+	limit = DefaultLimit
+
+	if r.Params.Offset != nil {
+		offset = *r.Params.Offset
+	}
+	if offset < 0 {
+		err = invalidInput("offset", "must be offset>=0")
+		return
+	}
+
+	if r.Params.Limit != nil {
+		limit = *r.Params.Limit
+	}
+	if limit < 1 || limit > 100 {
+		err = invalidInput("limit", "must be 1 <= limit <= 100")
+		return
+	}
 	return
 }
 
 func (r ListMemesRequestObject) GetParams() (
-	offset, limit int, sortBy string, err error) {
+	offset, limit int, sortBy string, filter models.MemeFilter, err error) {
 	offset = DefaultOffset
 	limit = DefaultLimit
 	sortBy = DefaultSortBy
@@ -99,13 +173,101 @@ func (r ListMemesRequestObject) GetParams() (
 		err = invalidInput("sortBy", "sortBy must be one of %v", AllowedSortBy)
 		return
 	}
+
+	filter, err = getMemeFilter(r.Params.BoardId, r.Params.MediaType, r.Params.CreatedAfter, r.Params.CreatedBefore, r.Params.Owner)
 	return
 }
 
-func getDescriptionMap(p SearchMemesParams) map[string]string {
+const DefaultMaxDistance = 10
+
+func (r SearchMemesByImageRequestObject) GetParams() (
+	offset, limit int, boards []models.BoardID, maxDistance int, err error) {
+	offset = DefaultOffset
+	limit = DefaultLimit
+	maxDistance = DefaultMaxDistance
+
+	if r.Params.Offset != nil {
+		offset = *r.Params.Offset
+	}
+	if offset < 0 {
+		err = invalidInput("offset", "must be offset>=0")
+		return
+	}
+
+	if r.Params.Limit != nil {
+		limit = *r.Params.Limit
+	}
+	if limit < 1 || limit > 100 {
+		err = invalidInput("limit", "must be 1 <= limit <= 100")
+		return
+	}
+
+	if r.Params.BoardId != nil {
+		for _, b := range *r.Params.BoardId {
+			boards = append(boards, models.BoardID(b))
+		}
+	}
+
+	if r.Params.MaxDistance != nil {
+		maxDistance = *r.Params.MaxDistance
+	}
+	if maxDistance < 0 || maxDistance > 64 {
+		err = invalidInput("maxDistance", "must be 0 <= maxDistance <= 64")
+		return
+	}
+	return
+}
+
+func (r SuggestQueriesRequestObject) GetParams() (
+	prefix string, limit int, err error) {
+	limit = DefaultLimit
+
+	prefix = r.Params.Prefix
+	if strings.TrimSpace(prefix) == "" {
+		err = invalidInput("prefix", "not empty prefix is expected")
+		return
+	}
+
+	if r.Params.Limit != nil {
+		limit = *r.Params.Limit
+	}
+	if limit < 1 || limit > 100 {
+		err = invalidInput("limit", "must be 1 <= limit <= 100")
+		return
+	}
+	return
+}
+
+const DefaultSuggestionLimit = 10
+
+func (r SuggestDescriptionRequestObject) GetParams() (
+	id models.MemeID, limit int, err error) {
+	id = models.MemeID(r.MemeID)
+	limit = DefaultSuggestionLimit
+
+	if r.Params.Limit != nil {
+		limit = *r.Params.Limit
+	}
+	if limit < 1 || limit > 50 {
+		err = invalidInput("limit", "must be 1 <= limit <= 50")
+		return
+	}
+	return
+}
+
+func getDescriptionMap(general, text, tags, source *string) map[string]string {
 	m := map[string]string{}
-	if p.General != nil {
-		m["general"] = *p.General
+	if general != nil {
+		m["general"] = *general
+	}
+	if text != nil {
+		m["text"] = *text
+	}
+	if tags != nil {
+		m["tags"] = *tags
+	}
+	if source != nil {
+		m["source"] = *source
 	}
 	return m
 }
@@ -127,6 +289,22 @@ func (r PostMemeRequestObject) GetParams() (
 	return
 }
 
+func (r PostMemeUsageRequestObject) GetParams() (
+	id models.MemeID, source string, err error) {
+	id = models.MemeID(r.MemeID)
+	if r.Body == nil {
+		err = invalidInput("body", "not empty body is expected")
+		return
+	}
+
+	source = string(r.Body.Source)
+	if !slices.Contains(models.UsageSources, source) {
+		err = invalidInput("source", "source must be one of %v", models.UsageSources)
+		return
+	}
+	return
+}
+
 func validateLogin(login string) error {
 	if len(login) < 3 || len(login) > 30 {
 		return fmt.Errorf("login length must be in [3;30]")
@@ -200,6 +378,102 @@ func (r UpdateBoardByIDRequestObject) GetParams() (
 	}
 	return
 }
+
+const (
+	MaxSynonymGroups    = 1000
+	MaxSynonymGroupSize = 50
+)
+
+// DefaultAnalyticsPeriod is how far back query analytics look by default.
+const DefaultAnalyticsPeriod = 30 * 24 * time.Hour
+
+func (r GetFrequentQueriesRequestObject) GetParams() (
+	board models.BoardID, since time.Time, limit int, err error) {
+	board = models.BoardID(r.BoardID)
+	since, limit, err = getAnalyticsParams(r.Params.Since, r.Params.Limit)
+	return
+}
+
+func (r GetFailedQueriesRequestObject) GetParams() (
+	board models.BoardID, since time.Time, maxScore *float64, limit int, err error) {
+	board = models.BoardID(r.BoardID)
+	since, limit, err = getAnalyticsParams(r.Params.Since, r.Params.Limit)
+	if err != nil {
+		return
+	}
+	maxScore = r.Params.MaxScore
+	if maxScore != nil && *maxScore < 0 {
+		err = invalidInput("maxScore", "must be maxScore >= 0")
+		return
+	}
+	return
+}
+
+func getAnalyticsParams(pSince *Since, pLimit *Limit) (
+	since time.Time, limit int, err error) {
+	since = time.Now().Add(-DefaultAnalyticsPeriod)
+	limit = DefaultLimit
+
+	if pSince != nil {
+		since = *pSince
+	}
+	if pLimit != nil {
+		limit = *pLimit
+	}
+	if limit < 1 || limit > 100 {
+		err = invalidInput("limit", "must be 1 <= limit <= 100")
+		return
+	}
+	return
+}
+
+const MaxSubscriptionWeight = 10
+
+func (r SetSubscriptionWeightRequestObject) GetParams() (
+	board models.BoardID, weight float64, err error) {
+	board = models.BoardID(r.BoardID)
+	if r.Body == nil {
+		err = invalidInput("body", "not empty body is expected")
+		return
+	}
+
+	weight = r.Body.Weight
+	if weight < 0 || weight > MaxSubscriptionWeight {
+		err = invalidInput("weight", "must be 0 <= weight <= %d", MaxSubscriptionWeight)
+		return
+	}
+	return
+}
+
+func (r SetBoardSynonymsRequestObject) GetParams() (
+	id models.BoardID, groups [][]string, err error) {
+	id = models.BoardID(r.BoardID)
+	if r.Body == nil {
+		err = invalidInput("body", "not empty body is expected")
+		return
+	}
+
+	if len(r.Body.Groups) > MaxSynonymGroups {
+		err = invalidInput("groups", "at most %d groups are allowed", MaxSynonymGroups)
+		return
+	}
+	for _, g := range r.Body.Groups {
+		group := []string{}
+		for _, w := range g {
+			w = strings.TrimSpace(w)
+			if w != "" && !slices.Contains(group, w) {
+				group = append(group, w)
+			}
+		}
+		if len(group) < 2 || len(group) > MaxSynonymGroupSize {
+			err = invalidInput("groups", "every group must have 2 to %d different words", MaxSynonymGroupSize)
+			return
+		}
+		groups = append(groups, group)
+	}
+	return
+}
+
 func (r ListBoardsRequestObject) GetParams() (
 	offset, limit int, sortBy string, err error) {
 	offset = DefaultOffset
@@ -231,3 +505,36 @@ func (r ListBoardsRequestObject) GetParams() (
 	}
 	return
 }
+
+func getMemeFilter[M ~string](boards *BoardIdFilter, mediaType *M, after *CreatedAfter, before *CreatedBefore, owner *Owner) (
+	filter models.MemeFilter, err error) {
+	if boards != nil {
+		for _, b := range *boards {
+			filter.Boards = append(filter.Boards, models.BoardID(b))
+		}
+	}
+
+	if mediaType != nil {
+		filter.MediaType = string(*mediaType)
+	}
+	if filter.MediaType != "" && !slices.Contains(models.MediaTypes, filter.MediaType) {
+		err = invalidInput("mediaType", "mediaType must be one of %v", models.MediaTypes)
+		return
+	}
+
+	if after != nil {
+		filter.CreatedAfter = *after
+	}
+	if before != nil {
+		filter.CreatedBefore = *before
+	}
+	if after != nil && before != nil && !filter.CreatedAfter.Before(filter.CreatedBefore) {
+		err = invalidInput("createdBefore", "must be createdAfter < createdBefore")
+		return
+	}
+
+	if owner != nil {
+		filter.Owner = models.UserID(*owner)
+	}
+	return
+}
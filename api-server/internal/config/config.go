@@ -11,12 +11,15 @@ type Config struct {
 	Server   ServerConfig   `yaml:"server"`
 	Database DatabaseConfig `yaml:"database"`
 	S3       S3Config       `yaml:"s3"`
+	Search   SearchConfig   `yaml:"search"`
 	Secrets  SecretConfig
 }
 
 type ServerConfig struct {
 	Port    int           `yaml:"port" env:"SERVER_PORT"`
 	Timeout time.Duration `yaml:"timeout"`
+	// AdminPort serves /debug/vars on localhost only.
+	AdminPort int `yaml:"adminPort" env:"SERVER_ADMIN_PORT" env-default:"1782"`
 }
 
 type DatabaseConfig struct {
@@ -33,6 +36,114 @@ type S3Config struct {
 	Bucket string `yaml:"bucket"`
 }
 
+type SearchConfig struct {
+	// Ranker is the name of a registered ranker: "default" (in-memory fuzzy
+	// ranking), "bm25" (in-memory BM25), "fts" (PostgreSQL full-text search)
+	// or "semantic" (description vectors, see Semantic).
+	Ranker string `yaml:"ranker" env:"SEARCH_RANKER" env-default:"default"`
+	// Experiment compares rankers instead of using Ranker when its mode is set.
+	Experiment ExperimentConfig `yaml:"experiment"`
+	// Analyzer is one of "stemming" (word segmentation and ru/en stemming)
+	// or "simple" (lowercase and split on spaces).
+	Analyzer string `yaml:"analyzer" env:"SEARCH_ANALYZER" env-default:"stemming"`
+	// FieldWeights weighs description fields matched by the general query.
+	FieldWeights map[string]float64 `yaml:"fieldWeights"`
+	StopWords    StopWordsConfig    `yaml:"stopWords"`
+	Popularity   PopularityConfig   `yaml:"popularity"`
+	Boost        BoostConfig        `yaml:"boost"`
+	Cursor       CursorConfig       `yaml:"cursor"`
+	Cache        CacheConfig        `yaml:"cache"`
+	Semantic     SemanticConfig     `yaml:"semantic"`
+	Log          SearchLogConfig    `yaml:"log"`
+}
+
+type StopWordsConfig struct {
+	// Languages selects built-in lists, "ru" and "en" are available.
+	Languages []string `yaml:"languages" env:"SEARCH_STOP_LANGUAGES" env-default:"ru,en"`
+	// Files are extra lists with a word per line.
+	Files []string `yaml:"files" env:"SEARCH_STOP_FILES"`
+	// Words are extra stop words.
+	Words []string `yaml:"words"`
+	// Boards maps board IDs to stop words of that board only.
+	Boards map[string][]string `yaml:"boards"`
+}
+
+type ExperimentConfig struct {
+	// Name seeds assignment of users, renaming reshuffles them.
+	Name string `yaml:"name" env-default:"experiment"`
+	// Mode is "bucket" to assign every user to one of Rankers or "interleave"
+	// to merge results of two Rankers. Empty disables the experiment.
+	Mode string `yaml:"mode" env:"SEARCH_EXPERIMENT_MODE"`
+	// Rankers are names of compared rankers.
+	Rankers []string `yaml:"rankers" env:"SEARCH_EXPERIMENT_RANKERS"`
+}
+
+// PopularityConfig blends relevance with usage of memes.
+type PopularityConfig struct {
+	Enabled bool `yaml:"enabled" env:"SEARCH_POPULARITY"`
+	// GlobalWeight and UserWeight weigh logarithms of usage counts by
+	// everyone and by the searching user.
+	GlobalWeight float64 `yaml:"globalWeight" env-default:"0.1"`
+	UserWeight   float64 `yaml:"userWeight" env-default:"0.3"`
+}
+
+// BoostConfig boosts memes by preferences of the searching user.
+type BoostConfig struct {
+	Enabled bool `yaml:"enabled" env:"SEARCH_BOOST"`
+	// OwnWeight multiplies scores of memes of boards the user owns.
+	OwnWeight float64 `yaml:"ownWeight" env-default:"1.5"`
+	// RecencyWeight is the bonus of a just created or updated meme, it
+	// halves every RecencyHalfLife.
+	RecencyWeight   float64       `yaml:"recencyWeight" env-default:"0.2"`
+	RecencyHalfLife time.Duration `yaml:"recencyHalfLife" env-default:"720h"`
+}
+
+// CursorConfig limits ranked results kept for cursor pagination.
+type CursorConfig struct {
+	// TTL is how long a cursor stays valid after its page was served.
+	TTL time.Duration `yaml:"ttl" env:"SEARCH_CURSOR_TTL" env-default:"10m"`
+	// Size is the maximum number of kept result snapshots.
+	Size int `yaml:"size" env:"SEARCH_CURSOR_SIZE" env-default:"1000"`
+}
+
+// CacheConfig limits ranked results cached for repeated searches.
+type CacheConfig struct {
+	// Size is the maximum number of cached searches, 0 disables the cache.
+	Size int `yaml:"size" env:"SEARCH_CACHE_SIZE" env-default:"10000"`
+	// TTL bounds staleness of popularity and other changes which don't
+	// invalidate cached results.
+	TTL time.Duration `yaml:"ttl" env:"SEARCH_CACHE_TTL" env-default:"5m"`
+}
+
+// SemanticConfig configures the "semantic" ranker.
+type SemanticConfig struct {
+	// Path is the file the vector index is persisted to, empty keeps it
+	// in memory only.
+	Path string `yaml:"path" env:"SEARCH_SEMANTIC_PATH" env-default:"data/vectors.gob"`
+	// Dim is the number of dimensions of description vectors.
+	Dim int `yaml:"dim" env-default:"512"`
+	// Neighbours is the number of nearest memes retrieved for a query.
+	Neighbours int `yaml:"neighbours" env-default:"100"`
+	// Blend weighs the fuzzy score of DefaultRanker against semantic
+	// similarity, 0 ranks by meaning only.
+	Blend float64 `yaml:"blend" env-default:"0.5"`
+	// MinScore drops memes scoring lower.
+	MinScore float64 `yaml:"minScore" env-default:"0.1"`
+}
+
+// SearchLogConfig configures the log of searches behind query analytics.
+type SearchLogConfig struct {
+	Enabled bool `yaml:"enabled" env:"SEARCH_LOG"`
+	// TopResults is the number of logged IDs of the first results.
+	TopResults int `yaml:"topResults" env-default:"5"`
+	// LowScore is the default top score below which a search is reported
+	// as failed.
+	LowScore float64 `yaml:"lowScore" env-default:"0.2"`
+	// QueueSize bounds searches waiting to be written, searches logged
+	// while the queue is full are dropped.
+	QueueSize int `yaml:"queueSize" env-default:"1000"`
+}
+
 type SecretConfig struct {
 	InviteCode string `env:"INVITE_CODE"`
 	JwtCode    string `env:"JWT_CODE"`
@@ -0,0 +1,173 @@
+// Package imagehash computes perceptual hashes of images. Visually similar
+// images get hashes with a small Hamming distance.
+package imagehash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// ErrUnsupported is returned for media which can't be decoded as an image.
+var ErrUnsupported = errors.New("unsupported image format")
+
+// ErrTooLarge is returned for images of more than MaxPixels pixels.
+var ErrTooLarge = errors.New("image is too large")
+
+// MaxPixels limits width×height of hashed images, so that a small file
+// declaring huge dimensions can't exhaust memory when it is decoded.
+const MaxPixels = 50_000_000
+
+// Hash is a 64 bit perceptual hash.
+type Hash uint64
+
+// Distance returns the number of bits which differ in a and b.
+func Distance(a, b Hash) int {
+	return bits.OnesCount64(uint64(a ^ b))
+}
+
+// Hashes are the perceptual hashes of an image.
+type Hashes struct {
+	DHash Hash
+	PHash Hash
+}
+
+// Distance returns the mean distance of dHash and pHash of h and o.
+func (h Hashes) Distance(o Hashes) int {
+	return (Distance(h.DHash, o.DHash) + Distance(h.PHash, o.PHash)) / 2
+}
+
+// Compute decodes a JPEG or PNG image and returns its hashes. It fails with
+// ErrUnsupported when body isn't a well-formed image and with ErrTooLarge
+// when it has more than MaxPixels pixels.
+func Compute(body []byte) (Hashes, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(body))
+	if errors.Is(err, image.ErrFormat) {
+		return Hashes{}, ErrUnsupported
+	}
+	if err != nil {
+		return Hashes{}, fmt.Errorf("%w: can't decode image header: %w", ErrUnsupported, err)
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return Hashes{}, fmt.Errorf("%w: %dx%d", ErrTooLarge, cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(body))
+	if errors.Is(err, image.ErrFormat) {
+		return Hashes{}, ErrUnsupported
+	}
+	if err != nil {
+		// truncated or corrupted images can't be hashed either
+		return Hashes{}, fmt.Errorf("%w: can't decode image: %w", ErrUnsupported, err)
+	}
+	return Hashes{DHash: DHash(img), PHash: PHash(img)}, nil
+}
+
+// DHash is the difference hash: every bit tells whether a pixel of the 9x8
+// grayscale thumbnail is brighter than its right neighbour.
+func DHash(img image.Image) Hash {
+	g := grayscale(img, 9, 8)
+	var h Hash
+	for y := range 8 {
+		for x := range 8 {
+			h <<= 1
+			if g[y][x] > g[y][x+1] {
+				h |= 1
+			}
+		}
+	}
+	return h
+}
+
+// PHash is the DCT hash: every bit tells whether a low frequency of the
+// 32x32 grayscale thumbnail is above the median of the lowest 8x8 frequencies.
+func PHash(img image.Image) Hash {
+	const size = 32
+	freq := dct(grayscale(img, size, size))
+
+	low := make([]float64, 0, 64)
+	for y := range 8 {
+		for x := range 8 {
+			low = append(low, freq[y][x])
+		}
+	}
+	// the DC term only reflects the mean brightness
+	median := slices.Clone(low[1:])
+	slices.Sort(median)
+	m := median[len(median)/2]
+
+	var h Hash
+	for _, f := range low {
+		h <<= 1
+		if f > m {
+			h |= 1
+		}
+	}
+	return h
+}
+
+// grayscale scales img down to w x h averaging source pixels covered by
+// every thumbnail pixel.
+func grayscale(img image.Image, w, h int) [][]float64 {
+	b := img.Bounds()
+	res := make([][]float64, h)
+	for y := range h {
+		res[y] = make([]float64, w)
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(b.Min.Y+(y+1)*b.Dy()/h, y0+1)
+		for x := range w {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(b.Min.X+(x+1)*b.Dx()/w, x0+1)
+			sum, n := 0.0, 0
+			for sy := y0; sy < y1 && sy < b.Max.Y; sy++ {
+				for sx := x0; sx < x1 && sx < b.Max.X; sx++ {
+					r, g, b, _ := img.At(sx, sy).RGBA()
+					sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+					n++
+				}
+			}
+			if n > 0 {
+				res[y][x] = sum / float64(n)
+			}
+		}
+	}
+	return res
+}
+
+// dct computes the 2D type II discrete cosine transform of a square matrix.
+func dct(m [][]float64) [][]float64 {
+	n := len(m)
+	cos := make([][]float64, n)
+	for k := range n {
+		cos[k] = make([]float64, n)
+		for i := range n {
+			cos[k][i] = math.Cos(math.Pi / float64(n) * (float64(i) + 0.5) * float64(k))
+		}
+	}
+
+	rows := make([][]float64, n)
+	for y := range n {
+		rows[y] = make([]float64, n)
+		for k := range n {
+			for i := range n {
+				rows[y][k] += m[y][i] * cos[k][i]
+			}
+		}
+	}
+	res := make([][]float64, n)
+	for k := range n {
+		res[k] = make([]float64, n)
+		for x := range n {
+			for i := range n {
+				res[k][x] += rows[i][x] * cos[k][i]
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,63 @@
+package imagehash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pattern(w, h int, invert bool) image.Image {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			fx, fy := float64(x)/float64(w), float64(y)/float64(h)
+			v := uint8(127 + 127*math.Sin(fx*7)*math.Cos(fy*4+fx))
+			if invert {
+				v = 255 - v
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img
+}
+
+func TestHashes(t *testing.T) {
+	orig := Hashes{DHash: DHash(pattern(200, 100, false)), PHash: PHash(pattern(200, 100, false))}
+	scaled := Hashes{DHash: DHash(pattern(640, 320, false)), PHash: PHash(pattern(640, 320, false))}
+	inverted := Hashes{DHash: DHash(pattern(200, 100, true)), PHash: PHash(pattern(200, 100, true))}
+
+	assert.LessOrEqual(t, orig.Distance(scaled), 4)
+	assert.Greater(t, orig.Distance(inverted), 20)
+}
+
+func TestCompute(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, pattern(64, 64, false)))
+
+	h, err := Compute(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, DHash(pattern(64, 64, false)), h.DHash)
+
+	_, err = Compute([]byte("not an image"))
+	assert.ErrorIs(t, err, ErrUnsupported)
+
+	_, err = Compute(buf.Bytes()[:buf.Len()/2])
+	assert.ErrorIs(t, err, ErrUnsupported)
+
+	// the header declares 100000x100000 pixels, the image isn't decoded
+	bomb := bytes.Clone(buf.Bytes())
+	ihdr := bomb[12:29]
+	binary.BigEndian.PutUint32(ihdr[4:], 100000)
+	binary.BigEndian.PutUint32(ihdr[8:], 100000)
+	binary.BigEndian.PutUint32(bomb[29:], crc32.ChecksumIEEE(ihdr))
+	_, err = Compute(bomb)
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
@@ -0,0 +1,43 @@
+package lru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEviction(t *testing.T) {
+	c := New[string, int](2, 0)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	_, _ = c.Get("a")
+	c.Add("c", 3)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, 2, c.Len())
+
+	c.RemoveFunc(func(k string, v int) bool { return v > 2 })
+	_, ok = c.Get("c")
+	assert.False(t, ok)
+}
+
+func TestTTL(t *testing.T) {
+	now := time.Now()
+	c := New[string, int](10, time.Minute)
+	c.now = func() time.Time { return now }
+	c.Add("a", 1)
+
+	now = now.Add(59 * time.Second)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Zero(t, c.Len())
+}
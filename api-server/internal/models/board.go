@@ -2,14 +2,48 @@ package models
 
 import (
 	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 )
 
 type BoardID string
 
 type Board struct {
-	ID    BoardID `json:"id"    db:"id"`
-	Owner UserID  `json:"owner" db:"owner_id"`
-	Name  string  `json:"name"  db:"name"`
+	ID       BoardID       `json:"id"       db:"id"`
+	Owner    UserID        `json:"owner"    db:"owner_id"`
+	Name     string        `json:"name"     db:"name"`
+	Settings BoardSettings `json:"settings" db:"settings"`
+}
+
+// BoardSettings customize search on a board. They are stored as JSON.
+type BoardSettings struct {
+	// Synonyms are groups of words and phrases which match each other.
+	Synonyms [][]string `json:"synonyms,omitempty"`
+}
+
+// Value implements driver.Valuer.
+func (s BoardSettings) Value() (driver.Value, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("can't marshal: %w", err)
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner.
+func (s *BoardSettings) Scan(src any) error {
+	*s = BoardSettings{}
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	case []byte:
+		return json.Unmarshal(v, s)
+	default:
+		return fmt.Errorf("unexpected settings type %T", src)
+	}
 }
 
 type BoardRepo interface {
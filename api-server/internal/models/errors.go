@@ -7,6 +7,7 @@ var ErrBoardNotFound = errors.New("Board not found")
 
 // Media
 var ErrMediaNotFound = errors.New("Media not found")
+var ErrMediaHashExists = errors.New("Media with this hash already exists on the board")
 
 // Meme
 var ErrMemeNotFound = errors.New("Meme not found")
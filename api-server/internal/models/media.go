@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"memesearch/internal/imagehash"
 )
 
 type MediaID MemeID
@@ -15,3 +16,24 @@ type MediaRepo interface {
 	GetMediaByID(ctx context.Context, id MediaID) (Media, error)
 	SetMediaByID(ctx context.Context, media Media) error
 }
+
+// MediaHash holds the content hash of a media and perceptual hashes
+// of it when it is an image.
+type MediaHash struct {
+	ID     MediaID
+	SHA256 string
+	Hashes *imagehash.Hashes
+	// Forced hashes may repeat SHA256 of other media of the board.
+	Forced bool
+}
+
+type MediaHashRepo interface {
+	// SetMediaHash fails with ErrMediaHashExists when hash isn't forced and
+	// another media of the board has the same SHA256 unforced.
+	SetMediaHash(ctx context.Context, hash MediaHash) error
+	// ListMediaHashes returns hashes of memes media on boards.
+	ListMediaHashes(ctx context.Context, boards []BoardID) ([]MediaHash, error)
+	// ListUnhashedMedia returns up to limit ids greater than after of memes
+	// whose media has no hash yet, in ascending order.
+	ListUnhashedMedia(ctx context.Context, after MediaID, limit int) ([]MediaID, error)
+}
@@ -2,6 +2,9 @@ package models
 
 import (
 	"context"
+	"path"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -16,12 +19,74 @@ type Meme struct {
 	UpdatedAt   time.Time         `json:"updated_at"`
 }
 
+const (
+	MediaTypePhoto = "photo"
+	MediaTypeVideo = "video"
+)
+
+// MediaTypes lists every known media type.
+var MediaTypes = []string{MediaTypePhoto, MediaTypeVideo}
+
+// MediaTypeExtensions maps media types to filename extensions of their files.
+var MediaTypeExtensions = map[string][]string{
+	MediaTypePhoto: {".jpg", ".jpeg", ".png", ".webp"},
+	MediaTypeVideo: {".mp4", ".mov", ".webm"},
+}
+
+// MediaType guesses meme media type by its filename extension.
+// Empty string is returned for memes without known media.
+func (m Meme) MediaType() string {
+	ext := strings.ToLower(path.Ext(m.Filename))
+	for t, exts := range MediaTypeExtensions {
+		if slices.Contains(exts, ext) {
+			return t
+		}
+	}
+	return ""
+}
+
+// MemeFilter narrows listed and searched memes. Zero fields don't filter.
+type MemeFilter struct {
+	// Boards keeps memes of any of the boards.
+	Boards []BoardID
+	// MediaType keeps memes of the media type.
+	MediaType string
+	// CreatedAfter keeps memes created after the moment.
+	CreatedAfter time.Time
+	// CreatedBefore keeps memes created before the moment.
+	CreatedBefore time.Time
+	// Owner keeps memes of boards owned by the user.
+	Owner UserID
+}
+
+// MatchBoard reports whether memes of board pass board and owner filters.
+func (f MemeFilter) MatchBoard(board Board) bool {
+	if len(f.Boards) > 0 && !slices.Contains(f.Boards, board.ID) {
+		return false
+	}
+	return f.Owner == "" || f.Owner == board.Owner
+}
+
+// MatchMeme reports whether meme passes media type and date filters.
+func (f MemeFilter) MatchMeme(meme Meme) bool {
+	if f.MediaType != "" && meme.MediaType() != f.MediaType {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && !meme.CreatedAt.After(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !meme.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+	return true
+}
 
 type MemeRepo interface {
 	InsertMeme(ctx context.Context, meme Meme) (MemeID, error)
 	GetMemeByID(ctx context.Context, id MemeID) (Meme, error)
 	GetMemesByBoardID(ctx context.Context, id BoardID, offset int, limit int) ([]Meme, error)
-	ListMemes(ctx context.Context, userID UserID, offset, limit int, sortBy string) ([]Meme, error)
+	ListMemes(ctx context.Context, userID UserID, filter MemeFilter, offset, limit int, sortBy string) ([]Meme, error)
+	ListAllMemes(ctx context.Context, offset, limit int) ([]Meme, error)
 	UpdateMeme(ctx context.Context, meme Meme) error
 	DeleteMeme(ctx context.Context, id MemeID) error
 }
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// SearchLogEntry records a search and how well it went.
+type SearchLogEntry struct {
+	UserID UserID
+	// Query is the normalized query text.
+	Query  string
+	Filter MemeFilter
+	// Boards are the searched boards.
+	Boards  []BoardID
+	Results int
+	// TopIDs are IDs of the first results.
+	TopIDs []MemeID
+	// TopScore is the score of the first result, 0 without results.
+	TopScore float64
+	// Ranker is the name of the ranker which scored the first result. It is
+	// empty for searches without words whose memes are listed unranked.
+	Ranker    string
+	Latency   time.Duration
+	CreatedAt time.Time
+}
+
+// QueryStat aggregates logged searches of a query.
+type QueryStat struct {
+	Query    string
+	Searches int
+	Users    int
+	// AvgResults is the average number of results.
+	AvgResults     float64
+	LastSearchedAt time.Time
+}
+
+type SearchLogRepo interface {
+	AddSearch(ctx context.Context, e SearchLogEntry) error
+	// FrequentQueries returns up to limit queries most often searched over
+	// the board since the moment.
+	FrequentQueries(ctx context.Context, board BoardID, since time.Time, limit int) ([]QueryStat, error)
+	// FailedQueries is FrequentQueries counting only searches without
+	// results or ranked by ranker with the top score below maxScore.
+	FailedQueries(ctx context.Context, board BoardID, since time.Time, ranker string, maxScore float64, limit int) ([]QueryStat, error)
+}
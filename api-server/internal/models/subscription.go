@@ -6,9 +6,23 @@ type Subsciption struct {
 	BoardID BoardID
 	UserID  UserID
 	Role    string
+	// Weight multiplies search scores of memes of the board for the user.
+	Weight float64
+}
+
+// BoardPreference is how a user relates to a board they own or are
+// subscribed to.
+type BoardPreference struct {
+	Own    bool
+	Weight float64
 }
 
 type SubsciptionRepo interface {
 	Subscribe(ctx context.Context, user UserID, board BoardID, role string) error
 	Unsubscribe(ctx context.Context, user UserID, board BoardID, role string) error
+	// SetSubscriptionWeight fails with ErrSubNotFound when the user isn't subscribed to the board.
+	SetSubscriptionWeight(ctx context.Context, user UserID, board BoardID, weight float64) error
+	// BoardPreferences returns preferences of the user for every board the
+	// user owns or is subscribed to.
+	BoardPreferences(ctx context.Context, user UserID) (map[BoardID]BoardPreference, error)
 }
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	UsageSourceInline = "inline"
+	UsageSourceSearch = "search"
+)
+
+// UsageSources are the places a meme can be sent from.
+var UsageSources = []string{UsageSourceInline, UsageSourceSearch}
+
+// UsageEvent records that a user sent a meme.
+type UsageEvent struct {
+	MemeID    MemeID
+	UserID    UserID
+	Source    string
+	CreatedAt time.Time
+}
+
+// UsageCount is the number of times a meme was used by everyone and by a user.
+type UsageCount struct {
+	Global int
+	User   int
+}
+
+type UsageRepo interface {
+	AddUsage(ctx context.Context, e UsageEvent) error
+	// UsageCounts returns usage counts of memes which were used at least once.
+	UsageCounts(ctx context.Context, memes []MemeID, user UserID) (map[MemeID]UsageCount, error)
+}
@@ -0,0 +1,98 @@
+// Package searcheval measures search quality of rankers offline on a labeled
+// dataset of queries over a snapshot of memes.
+package searcheval
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"memesearch/internal/models"
+)
+
+// Case is a labeled query of the dataset.
+type Case struct {
+	// Query is the general search query.
+	Query string `json:"query"`
+	// Boards limits the search to the boards, all boards of the snapshot
+	// are searched when empty.
+	Boards []models.BoardID `json:"boards,omitempty"`
+	// Expected are memes relevant to the query.
+	Expected []Judgement `json:"expected"`
+}
+
+// Judgement grades relevance of a meme to a query, higher is more relevant.
+// Memes which aren't judged have zero relevance.
+type Judgement struct {
+	MemeID    models.MemeID `json:"memeId"`
+	Relevance int           `json:"relevance"`
+}
+
+// relevance maps judged memes to their grades.
+func (c Case) relevance() map[models.MemeID]int {
+	res := make(map[models.MemeID]int, len(c.Expected))
+	for _, j := range c.Expected {
+		res[j.MemeID] = j.Relevance
+	}
+	return res
+}
+
+// ReadDataset reads cases, one JSON object per line.
+func ReadDataset(r io.Reader) ([]Case, error) {
+	return readLines[Case](r)
+}
+
+// ReadSnapshot reads memes, one JSON object per line.
+func ReadSnapshot(r io.Reader) ([]models.Meme, error) {
+	return readLines[models.Meme](r)
+}
+
+// WriteSnapshot writes memes, one JSON object per line.
+func WriteSnapshot(w io.Writer, memes []models.Meme) error {
+	enc := json.NewEncoder(w)
+	for _, m := range memes {
+		if err := enc.Encode(m); err != nil {
+			return fmt.Errorf("can't encode meme %s: %w", m.ID, err)
+		}
+	}
+	return nil
+}
+
+func readLines[T any](r io.Reader) ([]T, error) {
+	res := []T{}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var v T
+		if err := json.Unmarshal(sc.Bytes(), &v); err != nil {
+			return nil, fmt.Errorf("can't decode line %d: %w", line, err)
+		}
+		res = append(res, v)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("can't read: %w", err)
+	}
+	return res, nil
+}
+
+// snapshotRepo serves the snapshot to rankers which index memes themselves.
+// Only listing memes is supported.
+type snapshotRepo struct {
+	models.MemeRepo
+	memes []models.Meme
+}
+
+func NewSnapshotRepo(memes []models.Meme) models.MemeRepo {
+	return snapshotRepo{memes: memes}
+}
+
+// ListAllMemes implements models.MemeRepo.
+func (r snapshotRepo) ListAllMemes(_ context.Context, offset, limit int) ([]models.Meme, error) {
+	begin := min(offset, len(r.memes))
+	end := min(offset+limit, len(r.memes))
+	return r.memes[begin:end], nil
+}
@@ -0,0 +1,113 @@
+package searcheval
+
+import (
+	"context"
+	"fmt"
+	"memesearch/internal/models"
+	"memesearch/internal/searchindex"
+	"memesearch/internal/searchranker"
+	"slices"
+	"time"
+)
+
+// Report holds metrics of a ranker averaged over the dataset.
+type Report struct {
+	Ranker  string
+	Queries int
+	NDCG    float64
+	MRR     float64
+	Recall  float64
+	// Latency is the mean time spent ranking a query.
+	Latency time.Duration
+	// LatencyP95 is the 95th percentile of time spent ranking a query.
+	LatencyP95 time.Duration
+}
+
+// Evaluator ranks queries over a snapshot the way the API ranks them
+// over the search index.
+type Evaluator struct {
+	analyzer searchranker.Analyzer
+	fields   searchranker.FieldWeights
+	index    *searchindex.Index
+	boards   []models.BoardID
+	// K is the number of top results NDCG and recall are measured on.
+	K int
+}
+
+// NewEvaluator indexes memes for ranking queries with description fields
+// of fields typed in them.
+func NewEvaluator(an searchranker.Analyzer, fields searchranker.FieldWeights, memes []models.Meme, k int) *Evaluator {
+	e := &Evaluator{analyzer: an, fields: fields, index: searchindex.New(an), K: k}
+	for _, m := range memes {
+		e.index.Add(m)
+		if !slices.Contains(e.boards, m.BoardID) {
+			e.boards = append(e.boards, m.BoardID)
+		}
+	}
+	return e
+}
+
+// Run ranks every case with ranker and averages the metrics.
+func (e *Evaluator) Run(ctx context.Context, name string, ranker searchranker.Ranker, cases []Case) (Report, error) {
+	report := Report{Ranker: name, Queries: len(cases)}
+	if len(cases) == 0 {
+		return report, nil
+	}
+
+	latencies := make([]time.Duration, 0, len(cases))
+	for _, c := range cases {
+		start := time.Now()
+		ranked, err := e.rank(ctx, ranker, c)
+		if err != nil {
+			return Report{}, fmt.Errorf("can't rank %q: %w", c.Query, err)
+		}
+		latencies = append(latencies, time.Since(start))
+
+		rel := c.relevance()
+		report.NDCG += NDCG(ranked, rel, e.K)
+		report.MRR += ReciprocalRank(ranked, rel)
+		report.Recall += Recall(ranked, rel, e.K)
+	}
+
+	n := float64(len(cases))
+	report.NDCG /= n
+	report.MRR /= n
+	report.Recall /= n
+
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+	report.Latency = total / time.Duration(len(latencies))
+	slices.Sort(latencies)
+	report.LatencyP95 = latencies[(len(latencies)*95-1)/100]
+	return report, nil
+}
+
+// rank returns IDs of memes ranked for the case query.
+func (e *Evaluator) rank(ctx context.Context, ranker searchranker.Ranker, c Case) ([]models.MemeID, error) {
+	q, err := searchranker.ParseQuery(map[string]string{"general": c.Query}, e.analyzer, e.fields)
+	if err != nil {
+		return nil, err
+	}
+	if len(q.Clauses) == 0 {
+		return nil, nil
+	}
+
+	boards := []models.Board{}
+	for _, b := range e.boards {
+		if len(c.Boards) > 0 && !slices.Contains(c.Boards, b) {
+			continue
+		}
+		boards = append(boards, models.Board{ID: b})
+	}
+	res, err := e.index.Rank(ctx, ranker, boards, q, models.MemeFilter{}, searchranker.Request{})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]models.MemeID, 0, len(res))
+	for _, m := range res {
+		ids = append(ids, m.Meme.ID)
+	}
+	return ids, nil
+}
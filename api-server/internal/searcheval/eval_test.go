@@ -0,0 +1,49 @@
+package searcheval
+
+import (
+	"context"
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	rel := map[models.MemeID]int{"1": 3, "2": 1}
+
+	assert.InDelta(t, 1, NDCG([]models.MemeID{"1", "2", "3"}, rel, 10), 1e-9)
+	assert.Less(t, NDCG([]models.MemeID{"2", "1"}, rel, 10), 1.0)
+	assert.Zero(t, NDCG([]models.MemeID{"3"}, rel, 10))
+
+	assert.Equal(t, 0.5, ReciprocalRank([]models.MemeID{"3", "2"}, rel))
+	assert.Zero(t, ReciprocalRank(nil, rel))
+
+	assert.Equal(t, 0.5, Recall([]models.MemeID{"3", "1", "2"}, rel, 2))
+	assert.Equal(t, 1.0, Recall([]models.MemeID{"3", "1", "2"}, rel, 3))
+}
+
+func TestEvaluator(t *testing.T) {
+	cases, err := ReadDataset(strings.NewReader(`{"query": "кот", "expected": [{"memeId": "1", "relevance": 2}, {"memeId": "3", "relevance": 1}]}
+
+{"query": "собака", "boards": ["a"], "expected": [{"memeId": "2", "relevance": 1}]}
+`))
+	require.NoError(t, err)
+	require.Len(t, cases, 2)
+
+	memes := []models.Meme{
+		{ID: "1", BoardID: "a", Description: map[string]string{"general": "грустный кот"}},
+		{ID: "2", BoardID: "a", Description: map[string]string{"general": "весёлая собака"}},
+		{ID: "3", BoardID: "b", Description: map[string]string{"general": "кот и собака"}},
+	}
+	e := NewEvaluator(searchranker.StemmingAnalyzer{}, nil, memes, 10)
+	report, err := e.Run(context.Background(), "default", &searchranker.DefaultRanker{}, cases)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.Queries)
+	assert.Equal(t, 1.0, report.Recall)
+	assert.Equal(t, 1.0, report.MRR)
+	assert.Positive(t, report.Latency)
+}
@@ -0,0 +1,67 @@
+package searcheval
+
+import (
+	"math"
+	"memesearch/internal/models"
+	"slices"
+)
+
+// NDCG is the normalized discounted cumulative gain of the first k ranked
+// memes. It is 1 when memes are ordered by decreasing relevance.
+func NDCG(ranked []models.MemeID, relevance map[models.MemeID]int, k int) float64 {
+	grades := make([]int, 0, len(relevance))
+	for _, g := range relevance {
+		grades = append(grades, g)
+	}
+	slices.Sort(grades)
+	slices.Reverse(grades)
+
+	ideal := dcg(grades, k)
+	if ideal == 0 {
+		return 0
+	}
+	got := make([]int, 0, len(ranked))
+	for _, id := range ranked {
+		got = append(got, relevance[id])
+	}
+	return dcg(got, k) / ideal
+}
+
+func dcg(grades []int, k int) float64 {
+	res := 0.0
+	for i, g := range grades[:min(k, len(grades))] {
+		res += (math.Exp2(float64(g)) - 1) / math.Log2(float64(i+2))
+	}
+	return res
+}
+
+// ReciprocalRank is the inverse position of the first relevant meme or 0
+// when none was ranked.
+func ReciprocalRank(ranked []models.MemeID, relevance map[models.MemeID]int) float64 {
+	for i, id := range ranked {
+		if relevance[id] > 0 {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// Recall is the share of relevant memes found among the first k ranked.
+func Recall(ranked []models.MemeID, relevance map[models.MemeID]int, k int) float64 {
+	total := 0
+	for _, g := range relevance {
+		if g > 0 {
+			total++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	found := 0
+	for _, id := range ranked[:min(k, len(ranked))] {
+		if relevance[id] > 0 {
+			found++
+		}
+	}
+	return float64(found) / float64(total)
+}
@@ -0,0 +1,228 @@
+package searchindex
+
+import (
+	"context"
+	"fmt"
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+	"slices"
+	"sync"
+)
+
+// Index is an in-memory inverted index over meme descriptions.
+// It maps every description term to the memes (and their boards) containing it
+// and keeps a trigram index of the terms for fuzzy lookups. Description words
+// as they are written are kept as well to suggest queries.
+type Index struct {
+	analyzer searchranker.Analyzer
+
+	mu        sync.RWMutex
+	memes     map[models.MemeID]models.Meme
+	postings  map[string]postingList
+	grams     trigramIndex
+	boards    map[models.BoardID]*boardStats
+	words     map[string]wordFreq
+	wordGrams trigramIndex
+}
+
+// wordFreq is the number of memes of every board containing a word.
+type wordFreq map[models.BoardID]int
+
+type postingList map[models.MemeID]models.BoardID
+
+// boardStats holds corpus statistics of a single board.
+type boardStats struct {
+	docs   int
+	length int
+	df     map[string]int
+}
+
+// New creates an empty index which analyzes descriptions with an.
+func New(an searchranker.Analyzer) *Index {
+	return &Index{
+		analyzer:  an,
+		memes:     make(map[models.MemeID]models.Meme),
+		postings:  make(map[string]postingList),
+		grams:     make(trigramIndex),
+		boards:    make(map[models.BoardID]*boardStats),
+		words:     make(map[string]wordFreq),
+		wordGrams: make(trigramIndex),
+	}
+}
+
+// Build loads every meme from repo into the index.
+func (idx *Index) Build(ctx context.Context, repo models.MemeRepo) error {
+	const batchSize = 200
+	for offset := 0; ; offset += batchSize {
+		memes, err := repo.ListAllMemes(ctx, offset, batchSize)
+		if err != nil {
+			return fmt.Errorf("can't list memes with offset %d: %w", offset, err)
+		}
+		if len(memes) == 0 {
+			break
+		}
+		for _, m := range memes {
+			idx.Add(m)
+		}
+	}
+	return nil
+}
+
+// Add indexes meme replacing the previous version with the same ID.
+func (idx *Index) Add(meme models.Meme) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(meme.ID)
+	idx.memes[meme.ID] = meme
+
+	bs, ok := idx.boards[meme.BoardID]
+	if !ok {
+		bs = &boardStats{df: map[string]int{}}
+		idx.boards[meme.BoardID] = bs
+	}
+	bs.docs += 1
+	bs.length += len(searchranker.Tokens(meme, idx.analyzer))
+
+	for _, t := range idx.terms(meme) {
+		pl, ok := idx.postings[t]
+		if !ok {
+			pl = postingList{}
+			idx.postings[t] = pl
+			idx.grams.add(t)
+		}
+		pl[meme.ID] = meme.BoardID
+		bs.df[t] += 1
+	}
+
+	for _, w := range idx.surfaceWords(meme) {
+		wf, ok := idx.words[w]
+		if !ok {
+			wf = wordFreq{}
+			idx.words[w] = wf
+			idx.wordGrams.add(w)
+		}
+		wf[meme.BoardID] += 1
+	}
+}
+
+// Remove drops meme from the index. Unknown IDs are ignored.
+func (idx *Index) Remove(id models.MemeID) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+}
+
+func (idx *Index) remove(id models.MemeID) {
+	meme, ok := idx.memes[id]
+	if !ok {
+		return
+	}
+
+	bs := idx.boards[meme.BoardID]
+	bs.docs -= 1
+	bs.length -= len(searchranker.Tokens(meme, idx.analyzer))
+	if bs.docs == 0 {
+		delete(idx.boards, meme.BoardID)
+	}
+
+	for _, t := range idx.terms(meme) {
+		pl := idx.postings[t]
+		delete(pl, id)
+		if len(pl) == 0 {
+			delete(idx.postings, t)
+			idx.grams.remove(t)
+		}
+		bs.df[t] -= 1
+		if bs.df[t] == 0 {
+			delete(bs.df, t)
+		}
+	}
+
+	for _, w := range idx.surfaceWords(meme) {
+		wf := idx.words[w]
+		wf[meme.BoardID] -= 1
+		if wf[meme.BoardID] == 0 {
+			delete(wf, meme.BoardID)
+		}
+		if len(wf) == 0 {
+			delete(idx.words, w)
+			idx.wordGrams.remove(w)
+		}
+	}
+	delete(idx.memes, id)
+}
+
+// Candidates returns memes from boards that contain at least one term
+// similar to any of words. Memes are ordered by ID.
+func (idx *Index) Candidates(boards []models.BoardID, words []string) []models.Meme {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	visible := make(map[models.BoardID]struct{}, len(boards))
+	for _, b := range boards {
+		visible[b] = struct{}{}
+	}
+
+	ids := map[models.MemeID]struct{}{}
+	for _, w := range words {
+		for t := range idx.grams.similar(w) {
+			for id, board := range idx.postings[t] {
+				if _, ok := visible[board]; ok {
+					ids[id] = struct{}{}
+				}
+			}
+		}
+	}
+
+	memes := make([]models.Meme, 0, len(ids))
+	for id := range ids {
+		memes = append(memes, idx.memes[id])
+	}
+	slices.SortFunc(memes, func(a, b models.Meme) int {
+		switch {
+		case a.ID < b.ID:
+			return -1
+		case a.ID > b.ID:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return memes
+}
+
+// surfaceWords returns unique words of all meme description fields as they
+// are written skipping stop words.
+func (idx *Index) surfaceWords(meme models.Meme) []string {
+	seen := map[string]struct{}{}
+	res := []string{}
+	for _, d := range meme.Description {
+		for _, w := range searchranker.Words(d) {
+			if _, ok := seen[w]; ok {
+				continue
+			}
+			seen[w] = struct{}{}
+			if len(idx.analyzer.Analyze(w)) == 0 {
+				continue
+			}
+			res = append(res, w)
+		}
+	}
+	return res
+}
+
+// terms returns unique terms of all meme description fields.
+func (idx *Index) terms(meme models.Meme) []string {
+	seen := map[string]struct{}{}
+	res := []string{}
+	for _, w := range searchranker.Tokens(meme, idx.analyzer) {
+		if _, ok := seen[w]; ok {
+			continue
+		}
+		seen[w] = struct{}{}
+		res = append(res, w)
+	}
+	return res
+}
@@ -0,0 +1,113 @@
+package searchindex
+
+import (
+	"context"
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCandidates(t *testing.T) {
+	idx := New(searchranker.StemmingAnalyzer{})
+	idx.Add(models.Meme{ID: "1", BoardID: "a", Description: map[string]string{"general": "грустный кот"}})
+	idx.Add(models.Meme{ID: "2", BoardID: "a", Description: map[string]string{"general": "весёлая собака"}})
+	idx.Add(models.Meme{ID: "3", BoardID: "b", Description: map[string]string{"general": "котик спит"}})
+
+	ids := func(memes []models.Meme) []models.MemeID {
+		res := []models.MemeID{}
+		for _, m := range memes {
+			res = append(res, m.ID)
+		}
+		return res
+	}
+
+	assert.Equal(t, []models.MemeID{"1"}, ids(idx.Candidates([]models.BoardID{"a"}, []string{"кот"})))
+	assert.Equal(t, []models.MemeID{"2"}, ids(idx.Candidates([]models.BoardID{"a", "b"}, []string{"сабак"})))
+	assert.Contains(t, idx.SimilarTerms("кот"), "кот")
+	assert.NotContains(t, idx.SimilarTerms("кот"), "собак")
+
+	idx.Remove("1")
+	assert.Empty(t, idx.Candidates([]models.BoardID{"a"}, []string{"кот"}))
+	assert.NotContains(t, idx.SimilarTerms("кот"), "кот")
+}
+
+func TestSuggest(t *testing.T) {
+	idx := New(searchranker.StemmingAnalyzer{})
+	idx.Add(models.Meme{ID: "1", BoardID: "a", Description: map[string]string{"general": "грустный кот"}})
+	idx.Add(models.Meme{ID: "2", BoardID: "a", Description: map[string]string{"general": "грустная кошка"}})
+	idx.Add(models.Meme{ID: "3", BoardID: "a", Description: map[string]string{"general": "котик и кот"}})
+	idx.Add(models.Meme{ID: "4", BoardID: "b", Description: map[string]string{"general": "котлета"}})
+
+	words := func(cs []Completion) []string {
+		res := []string{}
+		for _, c := range cs {
+			res = append(res, c.Word)
+		}
+		return res
+	}
+	assert.Equal(t, []string{"кот", "котик", "кошка"}, words(idx.Complete([]models.BoardID{"a"}, "ко", 3)))
+	assert.Equal(t, []string{"котлета"}, words(idx.Complete([]models.BoardID{"b"}, "кот", 3)))
+	assert.ElementsMatch(t, []models.MemeID{"1", "3"}, idx.Complete([]models.BoardID{"a"}, "кот", 1)[0].Memes)
+
+	w, ok := idx.Correct([]models.BoardID{"a"}, "грустеый")
+	assert.True(t, ok)
+	assert.Equal(t, "грустный", w)
+	_, ok = idx.Correct([]models.BoardID{"b"}, "грустеый")
+	assert.False(t, ok)
+
+	idx.Remove("4")
+	assert.Empty(t, idx.Complete([]models.BoardID{"b"}, "кот", 3))
+}
+
+func TestTrigramCandidates(t *testing.T) {
+	ti := trigramIndex{}
+	for _, term := range []string{"котик", "кость", "крыса", "кабан", "собака", "грустный"} {
+		ti.add(term)
+	}
+
+	t.Run("Typos", func(t *testing.T) {
+		assert.Contains(t, ti.candidates("котек"), "котик")
+		assert.Contains(t, ti.candidates("сабака"), "собака")
+		assert.Contains(t, ti.candidates("сбака"), "собака")
+		assert.Contains(t, ti.candidates("собкаа"), "собака")
+		assert.Contains(t, ti.candidates("грустеый"), "грустный")
+		assert.Contains(t, ti.similar("сабака"), "собака")
+	})
+
+	t.Run("Same first letter", func(t *testing.T) {
+		assert.NotContains(t, ti.candidates("котек"), "кость")
+		assert.NotContains(t, ti.candidates("котек"), "крыса")
+		assert.NotContains(t, ti.candidates("котек"), "кабан")
+		assert.NotContains(t, ti.candidates("собака"), "грустный")
+	})
+}
+
+func TestRank(t *testing.T) {
+	idx := New(searchranker.DefaultAnalyzer)
+	idx.Add(models.Meme{ID: "1", BoardID: "a", Description: map[string]string{"general": "cat sleeps"}})
+	idx.Add(models.Meme{ID: "2", BoardID: "b", Description: map[string]string{"general": "cat sleeps"}})
+	idx.Add(models.Meme{ID: "3", BoardID: "a", Description: map[string]string{"general": "толстый кот"}})
+	boards := []models.Board{
+		{ID: "a", Settings: models.BoardSettings{Synonyms: [][]string{{"кот", "cat"}}}},
+		{ID: "b"},
+	}
+	rank := func(text string, boards []models.Board) []models.MemeID {
+		q, err := searchranker.Parse(text, searchranker.GeneralField, searchranker.DefaultAnalyzer, nil)
+		require.NoError(t, err)
+		res, err := idx.Rank(context.Background(), &searchranker.DefaultRanker{}, boards, q, models.MemeFilter{}, searchranker.Request{})
+		require.NoError(t, err)
+		ids := []models.MemeID{}
+		for _, m := range res {
+			ids = append(ids, m.Meme.ID)
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []models.MemeID{"1", "3"}, rank("кот", boards))
+	assert.ElementsMatch(t, []models.MemeID{"1", "2"}, rank("sleeps", boards))
+	assert.ElementsMatch(t, []models.MemeID{"2"}, rank("sleeps board:b", boards))
+	assert.Empty(t, rank("кот", boards[1:]))
+}
@@ -0,0 +1,48 @@
+package searchindex
+
+import (
+	"context"
+	"fmt"
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+	"slices"
+)
+
+// Rank ranks memes of visible boards matching query with ranker. Boards not
+// selected by query are skipped. Candidates are memes containing words of
+// query or their synonyms from board settings, merged with memes retrieved
+// by ranker when it is a searchranker.Retriever, which pass filter and
+// filters of query. Query, Stats, Vocabulary and Synonyms of req are set
+// here, the rest is passed to ranker as it is.
+func (idx *Index) Rank(ctx context.Context, ranker searchranker.Ranker, visible []models.Board, query searchranker.Query, filter models.MemeFilter, req searchranker.Request) ([]searchranker.ScroredMeme, error) {
+	boards := []models.BoardID{}
+	groups := map[models.BoardID][][]string{}
+	for _, b := range visible {
+		if len(query.Boards) > 0 && !slices.Contains(query.Boards, b.ID) {
+			continue
+		}
+		boards = append(boards, b.ID)
+		if len(b.Settings.Synonyms) > 0 {
+			groups[b.ID] = b.Settings.Synonyms
+		}
+	}
+	synonyms := searchranker.NewSynonyms(query.Analyzer(), groups)
+
+	req.Query = query
+	req.Stats = idx.Stats(boards)
+	req.Vocabulary = idx
+	req.Synonyms = synonyms
+
+	words := query.Words()
+	memes := idx.Candidates(boards, append(words, synonyms.Words(boards, words)...))
+	if r, ok := ranker.(searchranker.Retriever); ok {
+		more, err := r.Retrieve(ctx, boards, req)
+		if err != nil {
+			return nil, fmt.Errorf("can't retrieve candidates: %w", err)
+		}
+		memes = searchranker.Merge(memes, more)
+	}
+	memes = slices.DeleteFunc(memes, func(m models.Meme) bool { return !filter.MatchMeme(m) })
+	memes = searchranker.Filter(memes, query)
+	return ranker.Rank(ctx, memes, req)
+}
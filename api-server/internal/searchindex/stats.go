@@ -0,0 +1,63 @@
+package searchindex
+
+import (
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+)
+
+var _ searchranker.CorpusStats = &corpusStats{}
+
+// corpusStats combines statistics of the boards visible to a user.
+type corpusStats struct {
+	idx    *Index
+	boards []models.BoardID
+}
+
+// Stats returns statistics of the corpus made of boards.
+// They reflect the current index state on every call.
+func (idx *Index) Stats(boards []models.BoardID) searchranker.CorpusStats {
+	return &corpusStats{idx: idx, boards: boards}
+}
+
+func (s *corpusStats) DocCount() int {
+	s.idx.mu.RLock()
+	defer s.idx.mu.RUnlock()
+
+	n := 0
+	for _, b := range s.boards {
+		if bs, ok := s.idx.boards[b]; ok {
+			n += bs.docs
+		}
+	}
+	return n
+}
+
+func (s *corpusStats) AvgDocLen() float64 {
+	s.idx.mu.RLock()
+	defer s.idx.mu.RUnlock()
+
+	docs, length := 0, 0
+	for _, b := range s.boards {
+		if bs, ok := s.idx.boards[b]; ok {
+			docs += bs.docs
+			length += bs.length
+		}
+	}
+	if docs == 0 {
+		return 0
+	}
+	return float64(length) / float64(docs)
+}
+
+func (s *corpusStats) DocFreq(term string) int {
+	s.idx.mu.RLock()
+	defer s.idx.mu.RUnlock()
+
+	n := 0
+	for _, b := range s.boards {
+		if bs, ok := s.idx.boards[b]; ok {
+			n += bs.df[term]
+		}
+	}
+	return n
+}
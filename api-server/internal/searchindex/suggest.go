@@ -0,0 +1,90 @@
+package searchindex
+
+import (
+	"cmp"
+	"memesearch/internal/models"
+	"slices"
+	"strings"
+)
+
+// Completion is a description word starting with a completed prefix.
+type Completion struct {
+	Word string
+	// Freq is the number of visible memes containing Word.
+	Freq int
+	// Memes are the visible memes containing terms of Word.
+	Memes []models.MemeID
+}
+
+// Complete returns up to limit words of memes on boards which start with
+// prefix, most frequent first.
+func (idx *Index) Complete(boards []models.BoardID, prefix string, limit int) []Completion {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	res := []Completion{}
+	for w, wf := range idx.words {
+		if !strings.HasPrefix(w, prefix) {
+			continue
+		}
+		if f := wf.visible(boards); f > 0 {
+			res = append(res, Completion{Word: w, Freq: f})
+		}
+	}
+	slices.SortFunc(res, func(a, b Completion) int {
+		return cmp.Or(cmp.Compare(b.Freq, a.Freq), cmp.Compare(a.Word, b.Word))
+	})
+	res = res[:min(limit, len(res))]
+
+	for i := range res {
+		for _, t := range idx.analyzer.Analyze(res[i].Word) {
+			for id, board := range idx.postings[t] {
+				if slices.Contains(boards, board) {
+					res[i].Memes = append(res[i].Memes, id)
+				}
+			}
+		}
+	}
+	return res
+}
+
+// Correct returns the word of memes on boards most similar to word by
+// keyboard-aware Levenshtein distance. Words found on boards are returned
+// as they are, ok is false when nothing similar is found.
+func (idx *Index) Correct(boards []models.BoardID, word string) (string, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if wf, ok := idx.words[word]; ok && wf.visible(boards) > 0 {
+		return word, true
+	}
+
+	best, bestSim, bestFreq := "", 0.0, 0
+	for w, sim := range idx.wordGrams.similar(word) {
+		f := idx.words[w].visible(boards)
+		if f == 0 {
+			continue
+		}
+		if sim > bestSim || sim == bestSim && (f > bestFreq || f == bestFreq && w < best) {
+			best, bestSim, bestFreq = w, sim, f
+		}
+	}
+	return best, best != ""
+}
+
+// Contains reports whether word is a description word of memes on boards.
+func (idx *Index) Contains(boards []models.BoardID, word string) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return idx.words[word].visible(boards) > 0
+}
+
+// visible returns the number of memes on boards containing the word.
+func (wf wordFreq) visible(boards []models.BoardID) int {
+	n := 0
+	for _, b := range boards {
+		n += wf[b]
+	}
+	return n
+}
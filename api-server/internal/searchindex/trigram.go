@@ -0,0 +1,109 @@
+package searchindex
+
+import (
+	"memesearch/internal/searchranker"
+	"unicode/utf8"
+)
+
+var _ searchranker.Vocabulary = &Index{}
+
+// maxTypos is the number of edits a term may differ from a query word of n
+// runes by to be compared with it by keyboard-aware Levenshtein.
+func maxTypos(n int) int {
+	if n <= 5 {
+		return 1
+	}
+	return 2
+}
+
+// trigramIndex maps character trigrams to the indexed terms containing them.
+type trigramIndex map[string]map[string]struct{}
+
+// trigrams returns unique trigrams of word padded with two leading spaces
+// and one trailing space, so that short words still have a few of them.
+func trigrams(word string) []string {
+	rs := []rune("  " + word + " ")
+	seen := map[string]struct{}{}
+	res := make([]string, 0, len(rs))
+	for i := 0; i+3 <= len(rs); i++ {
+		g := string(rs[i : i+3])
+		if _, ok := seen[g]; ok {
+			continue
+		}
+		seen[g] = struct{}{}
+		res = append(res, g)
+	}
+	return res
+}
+
+func (ti trigramIndex) add(term string) {
+	for _, g := range trigrams(term) {
+		terms, ok := ti[g]
+		if !ok {
+			terms = map[string]struct{}{}
+			ti[g] = terms
+		}
+		terms[term] = struct{}{}
+	}
+}
+
+func (ti trigramIndex) remove(term string) {
+	for _, g := range trigrams(term) {
+		delete(ti[g], term)
+		if len(ti[g]) == 0 {
+			delete(ti, g)
+		}
+	}
+}
+
+// similar returns terms similar to word with their searchranker.Similarity.
+// Only terms within maxTypos edits of word by trigrams are compared.
+func (ti trigramIndex) similar(word string) map[string]float64 {
+	res := map[string]float64{}
+	for _, t := range ti.candidates(word) {
+		if s := searchranker.Similarity(word, t); s > 0 {
+			res[t] = s
+		}
+	}
+	return res
+}
+
+// candidates returns terms which may be within maxTypos edits of word. An
+// edit changes at most three trigrams of the padded word and the length by
+// one rune, so terms sharing fewer trigrams or longer or shorter by more are
+// left out.
+func (ti trigramIndex) candidates(word string) []string {
+	grams := trigrams(word)
+	shared := map[string]int{}
+	for _, g := range grams {
+		for t := range ti[g] {
+			shared[t] += 1
+		}
+	}
+
+	n := utf8.RuneCountInString(word)
+	typos := maxTypos(n)
+	need := max(len(grams)-3*typos, 1)
+	res := []string{}
+	for t, k := range shared {
+		if k >= need && abs(utf8.RuneCountInString(t)-n) <= typos {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// SimilarTerms implements searchranker.Vocabulary.
+func (idx *Index) SimilarTerms(word string) map[string]float64 {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return idx.grams.similar(word)
+}
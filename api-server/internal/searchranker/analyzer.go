@@ -0,0 +1,119 @@
+package searchranker
+
+import (
+	"fmt"
+	"memesearch/internal/models"
+	"strings"
+	"unicode"
+
+	"github.com/kljensen/snowball/english"
+	"github.com/kljensen/snowball/russian"
+	"github.com/rivo/uniseg"
+)
+
+// Analyzer turns text into terms that take part in matching.
+// The same analyzer must be used for descriptions and queries.
+type Analyzer interface {
+	Analyze(text string) []string
+}
+
+// DefaultAnalyzer is used when no analyzer is given.
+var DefaultAnalyzer Analyzer = StemmingAnalyzer{}
+
+// NewAnalyzer returns analyzer by its config name.
+func NewAnalyzer(name string) (Analyzer, error) {
+	switch name {
+	case "stemming":
+		return StemmingAnalyzer{}, nil
+	case "simple":
+		return SimpleAnalyzer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown analyzer: %s", name)
+	}
+}
+
+var _ Analyzer = SimpleAnalyzer{}
+
+// SimpleAnalyzer lowercases text and splits it on spaces.
+type SimpleAnalyzer struct{}
+
+// Analyze implements Analyzer.
+func (SimpleAnalyzer) Analyze(text string) []string {
+	res := []string{}
+	for _, w := range strings.Split(strings.ToLower(text), " ") {
+		if w == "" {
+			continue
+		}
+		res = append(res, w)
+	}
+	return res
+}
+
+var _ Analyzer = StemmingAnalyzer{}
+
+// StemmingAnalyzer splits text into Unicode words, strips punctuation,
+// folds ё into е and reduces russian and english words to their stems.
+type StemmingAnalyzer struct{}
+
+// Analyze implements Analyzer.
+func (StemmingAnalyzer) Analyze(text string) []string {
+	res := Words(text)
+	for i, w := range res {
+		res[i] = stem(w)
+	}
+	return res
+}
+
+// Words splits text into Unicode words, strips punctuation and folds ё into е.
+// Unlike analyzers it keeps words as they are written.
+func Words(text string) []string {
+	res := []string{}
+	state := -1
+	for len(text) > 0 {
+		var w string
+		w, text, state = uniseg.FirstWordInString(text, state)
+		w = normalize(w)
+		if w == "" {
+			continue
+		}
+		res = append(res, w)
+	}
+	return res
+}
+
+// normalize lowercases w, folds ё and drops everything but letters and digits.
+func normalize(w string) string {
+	return strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		switch {
+		case r == 'ё':
+			return 'е'
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return r
+		default:
+			return -1
+		}
+	}, w)
+}
+
+// stem stems w with the snowball stemmer of its alphabet.
+func stem(w string) string {
+	for _, r := range w {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			return russian.Stem(w, true)
+		case unicode.Is(unicode.Latin, r):
+			return english.Stem(w, true)
+		}
+	}
+	return w
+}
+
+// Tokens returns terms of all meme description fields including repeats.
+func Tokens(meme models.Meme, an Analyzer) []string {
+	res := []string{}
+	for _, d := range meme.Description {
+		res = append(res, analyzeBoard(an, meme.BoardID, d)...)
+	}
+	return res
+}
@@ -0,0 +1,53 @@
+package searchranker
+
+import (
+	"context"
+	"memesearch/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStemmingAnalyzer(t *testing.T) {
+	an := StemmingAnalyzer{}
+	tests := map[string][]string{
+		"Кот, кота и коту!": {"кот", "кот", "и", "кот"},
+		"ёжик ежик":         {"ежик", "ежик"},
+		"Running cats":      {"run", "cat"},
+		"кот-пёс 2024":      {"кот", "пес", "2024"},
+		"ок go":             {"ок", "go"},
+	}
+	for text, want := range tests {
+		assert.Equal(t, want, an.Analyze(text), text)
+	}
+}
+
+func TestStopFilter(t *testing.T) {
+	words, err := LoadStopWords([]string{"ru", "en"}, nil)
+	require.NoError(t, err)
+	sw := NewStopWords(StemmingAnalyzer{}, words, map[models.BoardID][]string{"1": {"мемы"}})
+	an := StopFilter{Analyzer: StemmingAnalyzer{}, Stop: sw}
+
+	assert.Equal(t, []string{"кот", "мем"}, an.Analyze("это кот и мем"))
+	assert.Equal(t, []string{"кот"}, an.AnalyzeBoard("1", "это кот и мем"))
+	assert.Equal(t, []string{"cat"}, an.Analyze("the cat"))
+}
+
+func TestRankersSkipStopClauses(t *testing.T) {
+	sw := NewStopWords(StemmingAnalyzer{}, nil, map[models.BoardID][]string{"1": {"мемы"}})
+	an := StopFilter{Analyzer: StemmingAnalyzer{}, Stop: sw}
+	memes := []models.Meme{
+		{ID: "1", BoardID: "1", Description: map[string]string{"general": "мемный"}},
+		{ID: "2", BoardID: "2", Description: map[string]string{"general": "мемный"}},
+	}
+	q, err := Parse("мемы", GeneralField, an, nil)
+	require.NoError(t, err)
+
+	for name, r := range map[string]Ranker{"default": &DefaultRanker{}, "bm25": NewBM25Ranker()} {
+		res, err := r.Rank(context.Background(), memes, Request{Query: q})
+		require.NoError(t, err, name)
+		require.Len(t, res, 1, name)
+		assert.Equal(t, models.MemeID("2"), res[0].Meme.ID, name)
+	}
+}
@@ -0,0 +1,160 @@
+package searchranker
+
+import (
+	"context"
+	"math"
+	"memesearch/internal/models"
+	"sort"
+	"strings"
+)
+
+var _ Ranker = &BM25Ranker{}
+
+// BM25Ranker scores memes with Okapi BM25 so that rare words weigh more than
+// words found in every meme. Typos are tolerated: a description word similar
+// to a query word counts as a hit weighted by their similarity.
+type BM25Ranker struct {
+	// K1 controls term frequency saturation.
+	K1 float64
+	// B controls description length normalization.
+	B float64
+	// Fields weighs description fields, DefaultFieldWeights is used when empty.
+	// A weighted hit counts as weight occurrences of the word.
+	Fields FieldWeights
+}
+
+func NewBM25Ranker() *BM25Ranker {
+	return &BM25Ranker{K1: 1.2, B: 0.75}
+}
+
+// Rank implements Ranker.
+func (br *BM25Ranker) Rank(ctx context.Context, memes []models.Meme, req Request) ([]ScroredMeme, error) {
+	stats := req.Stats
+	if stats == nil {
+		stats = NewCorpusStats(memes, req.Query.Analyzer())
+	}
+
+	matcher := newMatcher(req.Vocabulary)
+	res := make([]ScroredMeme, 0, len(memes))
+	for _, m := range memes {
+		s, corrections, matches := br.score(req, m, stats, matcher)
+		if s <= 0 {
+			continue
+		}
+		res = append(res, ScroredMeme{Score: s, Meme: m, Corrections: corrections, Explain: matches})
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Score > res[j].Score
+	})
+	return res, nil
+}
+
+// score sums the best contribution of every query clause. Variants of a term
+// are tried only when the term itself has no hits. Clauses made of stop words
+// of the meme board are left out.
+func (br *BM25Ranker) score(req Request, meme models.Meme, stats CorpusStats, m *matcher) (float64, map[string]string, []TermMatch) {
+	fields := br.Fields.orDefault()
+	an := req.Query.Analyzer()
+	clauses := searchedClauses(an, meme.BoardID, req.Synonyms.Expand(meme.BoardID, req.Query.Clauses))
+	if len(clauses) == 0 {
+		return 0, nil, nil
+	}
+	dws := fieldWords(meme.BoardID, meme.Description, an)
+	tfs := map[string]map[string]int{}
+	length := 0
+	for f, ws := range dws {
+		tf := map[string]int{}
+		for _, w := range ws {
+			tf[w] += 1
+		}
+		tfs[f] = tf
+		length += len(ws)
+	}
+	norm := 1 - br.B
+	if avg := stats.AvgDocLen(); avg > 0 {
+		norm += br.B * float64(length) / avg
+	}
+	saturate := func(tf float64) float64 {
+		return tf * (br.K1 + 1) / (tf + br.K1*norm)
+	}
+	termScore := func(t Term) TermMatch {
+		best := TermMatch{Threshold: termThreshold}
+		for f, weight := range fields.Searched(t.Field) {
+			if t.Exact {
+				// a phrase counts as an occurrence of each of its words
+				hits := exactHits(t.Words, dws[f])
+				if hits == 0 {
+					continue
+				}
+				s, idfs := 0.0, 0.0
+				for _, w := range t.Words {
+					idfs += idf(stats, w)
+					s += idf(stats, w) * saturate(weight*float64(hits))
+				}
+				if s > best.Score {
+					best = TermMatch{Field: f, Word: strings.Join(t.Words, " "), Similarity: 1,
+						Threshold: termThreshold, Weight: weight, IDF: idfs, Score: s}
+				}
+				continue
+			}
+			for w, cnt := range tfs[f] {
+				sim := m.similarity(t.Words[0], w)
+				if sim == 0 {
+					continue
+				}
+				s := idf(stats, w) * saturate(weight*sim*float64(cnt))
+				if s > best.Score {
+					best = TermMatch{Field: f, Word: w, Similarity: sim,
+						Threshold: termThreshold, Weight: weight, IDF: idf(stats, w), Score: s}
+				}
+			}
+		}
+		return best
+	}
+
+	return scoreClauses(clauses, 0, req.Explain, termScore)
+}
+
+func idf(stats CorpusStats, term string) float64 {
+	n := float64(stats.DocCount())
+	df := float64(stats.DocFreq(term))
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+var _ CorpusStats = &memesStats{}
+
+type memesStats struct {
+	docs   int
+	length int
+	df     map[string]int
+}
+
+// NewCorpusStats computes statistics of memes analyzed with an.
+func NewCorpusStats(memes []models.Meme, an Analyzer) CorpusStats {
+	s := &memesStats{df: map[string]int{}}
+	for _, m := range memes {
+		tokens := Tokens(m, an)
+		s.docs += 1
+		s.length += len(tokens)
+		seen := map[string]struct{}{}
+		for _, t := range tokens {
+			if _, ok := seen[t]; ok {
+				continue
+			}
+			seen[t] = struct{}{}
+			s.df[t] += 1
+		}
+	}
+	return s
+}
+
+func (s *memesStats) DocCount() int { return s.docs }
+
+func (s *memesStats) AvgDocLen() float64 {
+	if s.docs == 0 {
+		return 0
+	}
+	return float64(s.length) / float64(s.docs)
+}
+
+func (s *memesStats) DocFreq(term string) int { return s.df[term] }
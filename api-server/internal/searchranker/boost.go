@@ -0,0 +1,133 @@
+package searchranker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"memesearch/internal/models"
+	"sort"
+	"time"
+)
+
+// BoardPreferences reports how a user relates to boards.
+type BoardPreferences interface {
+	BoardPreferences(ctx context.Context, user models.UserID) (map[models.BoardID]models.BoardPreference, error)
+}
+
+// BoostWeights tune boosts of BoostRanker.
+type BoostWeights struct {
+	// Own multiplies scores of memes of boards the user owns.
+	Own float64
+	// Recency is the bonus of a meme created or updated just now. The bonus
+	// halves every HalfLife.
+	Recency  float64
+	HalfLife time.Duration
+}
+
+var DefaultBoostWeights = BoostWeights{Own: 1.5, Recency: 0.2, HalfLife: 30 * 24 * time.Hour}
+
+var _ Ranker = &BoostRanker{}
+
+// BoostRanker multiplies relevance scores of Ranker by preferences of the
+// searching user: memes of own boards, of boards weighted on subscription and
+// recently changed memes rank higher. Equal scores are ordered by recency.
+type BoostRanker struct {
+	Ranker      Ranker
+	Preferences BoardPreferences
+	Weights     BoostWeights
+	// Now returns the current time, time.Now when nil.
+	Now func() time.Time
+}
+
+// WithBoosts wraps r into a BoostRanker. The result is a Searcher or a
+// Retriever when r is.
+func WithBoosts(r Ranker, prefs BoardPreferences, w BoostWeights) Ranker {
+	br := &BoostRanker{Ranker: r, Preferences: prefs, Weights: w}
+	if s, ok := r.(Searcher); ok {
+		return &boostSearcher{BoostRanker: br, searcher: s}
+	}
+	if rt, ok := r.(Retriever); ok {
+		return &boostRetriever{BoostRanker: br, Retriever: rt}
+	}
+	return br
+}
+
+// Rank implements Ranker.
+func (br *BoostRanker) Rank(ctx context.Context, memes []models.Meme, req Request) ([]ScroredMeme, error) {
+	res, err := br.Ranker.Rank(ctx, memes, req)
+	if err != nil {
+		return nil, err
+	}
+	return br.boost(ctx, res, req.UserID)
+}
+
+func (br *BoostRanker) boost(ctx context.Context, res []ScroredMeme, user models.UserID) ([]ScroredMeme, error) {
+	if len(res) == 0 {
+		return res, nil
+	}
+	prefs, err := br.Preferences.BoardPreferences(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("can't get board preferences: %w", err)
+	}
+	now := time.Now()
+	if br.Now != nil {
+		now = br.Now()
+	}
+
+	for i := range res {
+		res[i].Score *= br.factor(res[i].Meme, prefs, now)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return changedAt(res[i].Meme).After(changedAt(res[j].Meme))
+	})
+	return res, nil
+}
+
+func (br *BoostRanker) factor(meme models.Meme, prefs map[models.BoardID]models.BoardPreference, now time.Time) float64 {
+	f := 1.0
+	if p, ok := prefs[meme.BoardID]; ok {
+		f *= p.Weight
+		if p.Own && br.Weights.Own > 0 {
+			f *= br.Weights.Own
+		}
+	}
+	if br.Weights.Recency > 0 && br.Weights.HalfLife > 0 {
+		age := max(now.Sub(changedAt(meme)), 0)
+		f *= 1 + br.Weights.Recency*math.Exp2(-float64(age)/float64(br.Weights.HalfLife))
+	}
+	return f
+}
+
+// changedAt is when meme was created or last updated.
+func changedAt(meme models.Meme) time.Time {
+	if meme.UpdatedAt.After(meme.CreatedAt) {
+		return meme.UpdatedAt
+	}
+	return meme.CreatedAt
+}
+
+var _ Searcher = &boostSearcher{}
+
+type boostSearcher struct {
+	*BoostRanker
+	searcher Searcher
+}
+
+// Search implements Searcher.
+func (bs *boostSearcher) Search(ctx context.Context, userID models.UserID, req Request) ([]ScroredMeme, error) {
+	res, err := bs.searcher.Search(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	return bs.boost(ctx, res, userID)
+}
+
+var _ Retriever = &boostRetriever{}
+
+type boostRetriever struct {
+	*BoostRanker
+	Retriever
+}
@@ -0,0 +1,52 @@
+package searchranker
+
+import (
+	"context"
+	"memesearch/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type boardPreferences map[models.BoardID]models.BoardPreference
+
+func (p boardPreferences) BoardPreferences(ctx context.Context, user models.UserID) (map[models.BoardID]models.BoardPreference, error) {
+	return p, nil
+}
+
+func TestBoostRanker(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	old := now.Add(-365 * 24 * time.Hour)
+	memes := []models.Meme{
+		{ID: "1", BoardID: "default", CreatedAt: old, Description: map[string]string{"general": "грустный кот"}},
+		{ID: "2", BoardID: "own", CreatedAt: old, Description: map[string]string{"general": "грустный кот"}},
+		{ID: "3", BoardID: "muted", CreatedAt: old, Description: map[string]string{"general": "грустный кот"}},
+		{ID: "4", BoardID: "default", CreatedAt: old, UpdatedAt: now.Add(-time.Hour), Description: map[string]string{"general": "грустный кот"}},
+	}
+	q, err := Parse("грустный кот", GeneralField, DefaultAnalyzer, nil)
+	require.NoError(t, err)
+
+	prefs := boardPreferences{
+		"default": {Weight: 1},
+		"own":     {Own: true, Weight: 1},
+		"muted":   {Weight: 0.5},
+	}
+	r := WithBoosts(&DefaultRanker{}, prefs, DefaultBoostWeights)
+	r.(*BoostRanker).Now = func() time.Time { return now }
+	res, err := r.Rank(context.Background(), memes, Request{Query: q})
+	require.NoError(t, err)
+
+	ids := []models.MemeID{}
+	for _, m := range res {
+		ids = append(ids, m.Meme.ID)
+	}
+	assert.Equal(t, []models.MemeID{"2", "4", "1", "3"}, ids)
+
+	// without boosts equal scores are ordered by recency
+	r = WithBoosts(&DefaultRanker{}, boardPreferences{}, BoostWeights{})
+	res, err = r.Rank(context.Background(), memes, Request{Query: q})
+	require.NoError(t, err)
+	assert.Equal(t, models.MemeID("4"), res[0].Meme.ID)
+}
@@ -0,0 +1,130 @@
+package searchranker
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"math/rand/v2"
+	"memesearch/internal/models"
+)
+
+const (
+	// ExperimentBucket assigns every user to one of the arms by a hash
+	// of the user ID.
+	ExperimentBucket = "bucket"
+	// ExperimentInterleave merges results of two arms with team-draft
+	// interleaving.
+	ExperimentInterleave = "interleave"
+)
+
+// Arm is a ranker compared in an experiment.
+type Arm struct {
+	Name   string
+	Ranker Ranker
+}
+
+var _ Ranker = &Experiment{}
+
+// Experiment compares rankers on real searches. Every result is attributed
+// to the arm which produced it through ScroredMeme.Ranker.
+type Experiment struct {
+	Name string
+	Mode string
+	Arms []Arm
+}
+
+func NewExperiment(name, mode string, arms []Arm) (*Experiment, error) {
+	switch {
+	case mode != ExperimentBucket && mode != ExperimentInterleave:
+		return nil, fmt.Errorf("unknown experiment mode %q", mode)
+	case mode == ExperimentInterleave && len(arms) != 2:
+		return nil, fmt.Errorf("interleaving needs 2 rankers, got %d", len(arms))
+	case len(arms) == 0:
+		return nil, fmt.Errorf("experiment has no rankers")
+	}
+	return &Experiment{Name: name, Mode: mode, Arms: arms}, nil
+}
+
+// Rank implements Ranker.
+func (e *Experiment) Rank(ctx context.Context, memes []models.Meme, req Request) ([]ScroredMeme, error) {
+	return e.Run(ctx, req.UserID, req.Query.Text(), func(r Ranker) ([]ScroredMeme, error) {
+		return r.Rank(ctx, memes, req)
+	})
+}
+
+// Run ranks with the arms chosen for user by rank, which lets callers handle
+// arms which are Searchers. Interleaving of the same user and query is
+// deterministic, so pages of results are consistent.
+func (e *Experiment) Run(ctx context.Context, user models.UserID, query string, rank func(r Ranker) ([]ScroredMeme, error)) ([]ScroredMeme, error) {
+	if e.Mode == ExperimentBucket {
+		arm := e.Arms[hash(e.Name, string(user))%uint64(len(e.Arms))]
+		res, err := rank(arm.Ranker)
+		if err != nil {
+			return nil, fmt.Errorf("can't rank with %s: %w", arm.Name, err)
+		}
+		for i := range res {
+			res[i].Ranker = arm.Name
+		}
+		slog.DebugContext(ctx, "Experiment arm", "experiment", e.Name, "user", user, "arm", arm.Name)
+		return res, nil
+	}
+
+	a, b := e.Arms[0], e.Arms[1]
+	ra, err := rank(a.Ranker)
+	if err != nil {
+		return nil, fmt.Errorf("can't rank with %s: %w", a.Name, err)
+	}
+	rb, err := rank(b.Ranker)
+	if err != nil {
+		return nil, fmt.Errorf("can't rank with %s: %w", b.Name, err)
+	}
+	rnd := rand.New(rand.NewPCG(hash(e.Name, string(user)), hash(query)))
+	return interleave(ra, rb, a.Name, b.Name, rnd), nil
+}
+
+// interleave merges a and b with team-draft interleaving: the team which
+// picked fewer results, or a random one on ties, picks its best result
+// not picked yet.
+func interleave(a, b []ScroredMeme, nameA, nameB string, rnd *rand.Rand) []ScroredMeme {
+	res := make([]ScroredMeme, 0, max(len(a), len(b)))
+	picked := map[models.MemeID]struct{}{}
+	next := func(list []ScroredMeme, i *int) (ScroredMeme, bool) {
+		for ; *i < len(list); *i++ {
+			if _, ok := picked[list[*i].Meme.ID]; !ok {
+				return list[*i], true
+			}
+		}
+		return ScroredMeme{}, false
+	}
+
+	ia, ib, na, nb := 0, 0, 0, 0
+	for {
+		ma, okA := next(a, &ia)
+		mb, okB := next(b, &ib)
+		if !okA && !okB {
+			return res
+		}
+		pickA := okA && (!okB || na < nb || na == nb && rnd.IntN(2) == 0)
+		if pickA {
+			ma.Ranker = nameA
+			res = append(res, ma)
+			na++
+			picked[ma.Meme.ID] = struct{}{}
+		} else {
+			mb.Ranker = nameB
+			res = append(res, mb)
+			nb++
+			picked[mb.Meme.ID] = struct{}{}
+		}
+	}
+}
+
+func hash(parts ...string) uint64 {
+	h := fnv.New64a()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
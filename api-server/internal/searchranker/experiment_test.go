@@ -0,0 +1,65 @@
+package searchranker
+
+import (
+	"context"
+	"memesearch/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedRanker []models.MemeID
+
+func (f fixedRanker) Rank(ctx context.Context, memes []models.Meme, req Request) ([]ScroredMeme, error) {
+	res := []ScroredMeme{}
+	for _, id := range f {
+		res = append(res, ScroredMeme{Meme: models.Meme{ID: id}})
+	}
+	return res, nil
+}
+
+func TestInterleaveExperiment(t *testing.T) {
+	e, err := NewExperiment("test", ExperimentInterleave, []Arm{
+		{Name: "a", Ranker: fixedRanker{"1", "2", "3"}},
+		{Name: "b", Ranker: fixedRanker{"2", "4"}},
+	})
+	require.NoError(t, err)
+
+	res, err := e.Rank(context.Background(), nil, Request{UserID: "user"})
+	require.NoError(t, err)
+
+	ids, teams := []models.MemeID{}, map[string]int{}
+	for _, m := range res {
+		ids = append(ids, m.Meme.ID)
+		teams[m.Ranker]++
+	}
+	assert.ElementsMatch(t, []models.MemeID{"1", "2", "3", "4"}, ids)
+	assert.Equal(t, map[string]int{"a": 2, "b": 2}, teams)
+
+	again, err := e.Rank(context.Background(), nil, Request{UserID: "user"})
+	require.NoError(t, err)
+	assert.Equal(t, res, again)
+}
+
+func TestBucketExperiment(t *testing.T) {
+	e, err := NewExperiment("test", ExperimentBucket, []Arm{
+		{Name: "a", Ranker: fixedRanker{"1"}},
+		{Name: "b", Ranker: fixedRanker{"2"}},
+	})
+	require.NoError(t, err)
+
+	arms := map[string]bool{}
+	for _, u := range []models.UserID{"1", "2", "3", "4", "5", "6", "7", "8"} {
+		res, err := e.Rank(context.Background(), nil, Request{UserID: u})
+		require.NoError(t, err)
+		again, err := e.Rank(context.Background(), nil, Request{UserID: u})
+		require.NoError(t, err)
+		assert.Equal(t, res, again)
+		arms[res[0].Ranker] = true
+	}
+	assert.Len(t, arms, 2)
+
+	_, err = NewExperiment("test", ExperimentInterleave, []Arm{{Name: "a"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package searchranker
+
+// TermMatch explains how a query term contributed to a meme score.
+type TermMatch struct {
+	// Term is the query term as it was typed.
+	Term string
+	// Variant is the corrected spelling of Term which matched, if any.
+	Variant string
+	// Field is the description field Word was found in.
+	Field string
+	// Word is the description word (or phrase) which matched Term best.
+	Word string
+	// Similarity of Term to Word.
+	Similarity float64
+	// Threshold is the similarity Word had to exceed.
+	Threshold float64
+	// Weight of Field.
+	Weight float64
+	// IDF is the inverse document frequency of Word for rankers using it.
+	IDF float64
+	// Score is the contribution of the term to its clause.
+	Score float64
+	// Used is set for the term which scored its clause.
+	Used bool
+}
+
+// scoreClauses scores every clause with the best match of its terms found
+// by score. It returns the total of clause scores, corrections of terms which
+// matched through variants and, when explain is set, matches of every term.
+func scoreClauses(clauses []Clause, poor float64, explain bool, score func(t Term) TermMatch) (float64, map[string]string, []TermMatch) {
+	var corrections map[string]string
+	var matches []TermMatch
+	total := 0.0
+	for _, c := range clauses {
+		best := -1
+		cms := make([]TermMatch, 0, len(c))
+		for _, t := range c {
+			tm := withVariants(t, poor, score)
+			if tm.Score > 0 && (best < 0 || tm.Score > cms[best].Score) {
+				best = len(cms)
+			}
+			cms = append(cms, tm)
+		}
+		if best >= 0 {
+			cms[best].Used = true
+			total += cms[best].Score
+			if v := cms[best].Variant; v != "" {
+				if corrections == nil {
+					corrections = map[string]string{}
+				}
+				corrections[cms[best].Term] = v
+			}
+		}
+		if explain {
+			matches = append(matches, cms...)
+		}
+	}
+	return total, corrections, matches
+}
@@ -0,0 +1,47 @@
+package searchranker
+
+import "memesearch/internal/models"
+
+// GeneralField is the query field which is matched against every weighted
+// description field. Any other query field is matched against the
+// description field with the same name only.
+const GeneralField = "general"
+
+// FieldWeights maps description fields to their weight in a score.
+type FieldWeights map[string]float64
+
+// DefaultFieldWeights searches only the general description.
+var DefaultFieldWeights = FieldWeights{GeneralField: 1}
+
+// Weight returns the weight of field. Fields which aren't configured
+// weigh 1 when they are searched explicitly.
+func (fw FieldWeights) Weight(field string) float64 {
+	if w, ok := fw[field]; ok {
+		return w
+	}
+	return 1
+}
+
+// Searched returns description fields matched by a query on field.
+func (fw FieldWeights) Searched(field string) FieldWeights {
+	if field == GeneralField {
+		return fw
+	}
+	return FieldWeights{field: fw.Weight(field)}
+}
+
+func (fw FieldWeights) orDefault() FieldWeights {
+	if len(fw) == 0 {
+		return DefaultFieldWeights
+	}
+	return fw
+}
+
+// fieldWords analyzes every description field of a meme on board.
+func fieldWords(board models.BoardID, dsc map[string]string, an Analyzer) map[string][]string {
+	res := make(map[string][]string, len(dsc))
+	for f, d := range dsc {
+		res[f] = analyzeBoard(an, board, d)
+	}
+	return res
+}
@@ -0,0 +1,55 @@
+package searchranker
+
+// Vocabulary knows every description term of the ranked memes and
+// pre-selects the ones similar to a query word.
+type Vocabulary interface {
+	// SimilarTerms returns terms similar to word with their Similarity.
+	SimilarTerms(word string) map[string]float64
+}
+
+// matcher memoizes similarity of query words to description words
+// for the duration of a single Rank call.
+type matcher struct {
+	vocab Vocabulary
+	terms map[string]map[string]float64
+	pairs map[[2]string]float64
+}
+
+func newMatcher(vocab Vocabulary) *matcher {
+	return &matcher{
+		vocab: vocab,
+		terms: map[string]map[string]float64{},
+		pairs: map[[2]string]float64{},
+	}
+}
+
+// similarity returns Similarity of query word q to description word w.
+// With a vocabulary only the terms it pre-selected are compared.
+func (m *matcher) similarity(q, w string) float64 {
+	if m.vocab != nil {
+		sims, ok := m.terms[q]
+		if !ok {
+			sims = m.vocab.SimilarTerms(q)
+			m.terms[q] = sims
+		}
+		return sims[w]
+	}
+
+	key := [2]string{q, w}
+	s, ok := m.pairs[key]
+	if !ok {
+		s = Similarity(q, w)
+		m.pairs[key] = s
+	}
+	return s
+}
+
+// Similarity returns similarity of a and b in (termThreshold, 1]
+// or 0 when they are too far apart to contribute to a score.
+func Similarity(a, b string) float64 {
+	s := 1 - normlizedLevenstainDist(a, b)
+	if s <= termThreshold {
+		return 0
+	}
+	return s
+}
@@ -0,0 +1,101 @@
+package searchranker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"memesearch/internal/models"
+	"sort"
+)
+
+// UsageCounter reports how often memes were sent by everyone and by a user.
+type UsageCounter interface {
+	UsageCounts(ctx context.Context, memes []models.MemeID, user models.UserID) (map[models.MemeID]models.UsageCount, error)
+}
+
+// PopularityWeights weigh logarithms of usage counts in the score boost.
+type PopularityWeights struct {
+	Global float64
+	User   float64
+}
+
+var DefaultPopularityWeights = PopularityWeights{Global: 0.1, User: 0.3}
+
+var _ Ranker = &PopularityRanker{}
+
+// PopularityRanker multiplies relevance scores of Ranker by a boost growing
+// with global and per-user usage of memes, so that frequently used memes
+// rank above rarely used ones with similar descriptions.
+type PopularityRanker struct {
+	Ranker  Ranker
+	Usage   UsageCounter
+	Weights PopularityWeights
+}
+
+// WithPopularity wraps r into a PopularityRanker. The result is a Searcher
+// or a Retriever when r is.
+func WithPopularity(r Ranker, usage UsageCounter, w PopularityWeights) Ranker {
+	pr := &PopularityRanker{Ranker: r, Usage: usage, Weights: w}
+	if s, ok := r.(Searcher); ok {
+		return &popularitySearcher{PopularityRanker: pr, searcher: s}
+	}
+	if rt, ok := r.(Retriever); ok {
+		return &popularityRetriever{PopularityRanker: pr, Retriever: rt}
+	}
+	return pr
+}
+
+// Rank implements Ranker.
+func (pr *PopularityRanker) Rank(ctx context.Context, memes []models.Meme, req Request) ([]ScroredMeme, error) {
+	res, err := pr.Ranker.Rank(ctx, memes, req)
+	if err != nil {
+		return nil, err
+	}
+	return pr.boost(ctx, res, req.UserID)
+}
+
+func (pr *PopularityRanker) boost(ctx context.Context, res []ScroredMeme, user models.UserID) ([]ScroredMeme, error) {
+	if len(res) == 0 {
+		return res, nil
+	}
+	ids := make([]models.MemeID, 0, len(res))
+	for _, m := range res {
+		ids = append(ids, m.Meme.ID)
+	}
+	counts, err := pr.Usage.UsageCounts(ctx, ids, user)
+	if err != nil {
+		return nil, fmt.Errorf("can't get usage counts: %w", err)
+	}
+
+	for i := range res {
+		c := counts[res[i].Meme.ID]
+		res[i].Score *= 1 + pr.Weights.Global*math.Log1p(float64(c.Global)) + pr.Weights.User*math.Log1p(float64(c.User))
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Score > res[j].Score
+	})
+	return res, nil
+}
+
+var _ Searcher = &popularitySearcher{}
+
+type popularitySearcher struct {
+	*PopularityRanker
+	searcher Searcher
+}
+
+// Search implements Searcher.
+func (ps *popularitySearcher) Search(ctx context.Context, userID models.UserID, req Request) ([]ScroredMeme, error) {
+	res, err := ps.searcher.Search(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	return ps.boost(ctx, res, userID)
+}
+
+var _ Retriever = &popularityRetriever{}
+
+type popularityRetriever struct {
+	*PopularityRanker
+	Retriever
+}
@@ -0,0 +1,34 @@
+package searchranker
+
+import (
+	"context"
+	"memesearch/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type usageCounts map[models.MemeID]models.UsageCount
+
+func (u usageCounts) UsageCounts(ctx context.Context, memes []models.MemeID, user models.UserID) (map[models.MemeID]models.UsageCount, error) {
+	return u, nil
+}
+
+func TestPopularityRanker(t *testing.T) {
+	memes := []models.Meme{
+		{ID: "rare", Description: map[string]string{"general": "грустный кот"}},
+		{ID: "used", Description: map[string]string{"general": "грустный кот"}},
+		{ID: "other", Description: map[string]string{"general": "веселый пес"}},
+	}
+	q, err := Parse("грустный кот", GeneralField, DefaultAnalyzer, nil)
+	require.NoError(t, err)
+
+	r := WithPopularity(&DefaultRanker{}, usageCounts{"used": {Global: 5, User: 1}}, DefaultPopularityWeights)
+	res, err := r.Rank(context.Background(), memes, Request{Query: q})
+	require.NoError(t, err)
+
+	require.Len(t, res, 2)
+	assert.Equal(t, models.MemeID("used"), res[0].Meme.ID)
+	assert.Greater(t, res[0].Score, res[1].Score)
+}
@@ -0,0 +1,324 @@
+package searchranker
+
+import (
+	"errors"
+	"fmt"
+	"memesearch/internal/models"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Query is a parsed search query.
+//
+// Grammar:
+//
+//	word            fuzzy match of a word
+//	"exact phrase"  words in a row, matched exactly
+//	-term           drops memes containing term exactly
+//	a OR b          either a or b
+//	field:term      term matched against description field only, other
+//	                words with a colon like 12:30 or URLs are plain text
+//	board:<id>      only memes of board id
+//	type:<type>     only memes of media type (photo, video)
+type Query struct {
+	// Clauses are scored separately and averaged, a clause scores
+	// as the best of its alternative terms.
+	Clauses []Clause
+	// Excluded terms drop memes containing them.
+	Excluded []Term
+	// Boards restricts search to the listed boards when not empty.
+	Boards []models.BoardID
+	// MediaType restricts search to memes of this media type when not empty.
+	MediaType string
+
+	analyzer Analyzer
+}
+
+// Clause is a set of alternative terms joined with OR.
+type Clause []Term
+
+// Term is a single matched unit of a query.
+type Term struct {
+	// Text is the term as it was typed.
+	Text string
+	// Field is the description field the term is matched against.
+	Field string
+	// Words of the term, several words are possible only for exact terms.
+	Words []string
+	// Exact terms match only when their words are found in a row.
+	Exact bool
+	// Variants are alternative spellings of non exact terms which are
+	// scored when the term itself matches poorly.
+	Variants []Variant
+}
+
+var ErrQuerySyntax = errors.New("bad query syntax")
+
+// ParseQuery parses text of every query field with words analyzed by an.
+// Terms without explicit field are matched against the field their text came from.
+// Description fields of fields can be typed explicitly, see Parse.
+func ParseQuery(query map[string]string, an Analyzer, fields FieldWeights) (Query, error) {
+	names := make([]string, 0, len(query))
+	for f := range query {
+		names = append(names, f)
+	}
+	slices.Sort(names)
+
+	res := Query{analyzer: an}
+	for _, f := range names {
+		q, err := Parse(query[f], f, an, fields)
+		if err != nil {
+			return Query{}, fmt.Errorf("can't parse %s: %w", f, err)
+		}
+		if q.MediaType != "" && res.MediaType != "" && q.MediaType != res.MediaType {
+			return Query{}, fmt.Errorf("%w: conflicting media types", ErrQuerySyntax)
+		}
+		if q.MediaType != "" {
+			res.MediaType = q.MediaType
+		}
+		res.Clauses = append(res.Clauses, q.Clauses...)
+		res.Excluded = append(res.Excluded, q.Excluded...)
+		res.Boards = append(res.Boards, q.Boards...)
+	}
+	return res, nil
+}
+
+// Parse parses text with words analyzed by an. Terms without explicit field
+// are matched against field. Only the general field and description fields
+// of fields (DefaultFieldWeights when empty) can be typed explicitly.
+func Parse(text string, field string, an Analyzer, fields FieldWeights) (Query, error) {
+	q := Query{analyzer: an}
+	or := false
+	for _, tok := range lex(text, fields.orDefault()) {
+		if !tok.quoted && !tok.negated && tok.field == "" && tok.text == "OR" {
+			or = len(q.Clauses) > 0
+			continue
+		}
+
+		switch tok.field {
+		case "board":
+			if tok.negated {
+				return Query{}, fmt.Errorf("%w: board filter can't be negated", ErrQuerySyntax)
+			}
+			q.Boards = append(q.Boards, models.BoardID(tok.text))
+			continue
+		case "type":
+			if tok.negated {
+				return Query{}, fmt.Errorf("%w: type filter can't be negated", ErrQuerySyntax)
+			}
+			if !slices.Contains(models.MediaTypes, tok.text) {
+				return Query{}, fmt.Errorf("%w: type must be one of %v", ErrQuerySyntax, models.MediaTypes)
+			}
+			if q.MediaType != "" && q.MediaType != tok.text {
+				return Query{}, fmt.Errorf("%w: conflicting media types", ErrQuerySyntax)
+			}
+			q.MediaType = tok.text
+			continue
+		}
+
+		for _, t := range terms(tok, field, an) {
+			switch {
+			case tok.negated:
+				q.Excluded = append(q.Excluded, t)
+			case or:
+				q.Clauses[len(q.Clauses)-1] = append(q.Clauses[len(q.Clauses)-1], t)
+			default:
+				q.Clauses = append(q.Clauses, Clause{t})
+			}
+			or = false
+		}
+		or = false
+	}
+	return q, nil
+}
+
+// terms makes terms of tok. Words of a quoted token form a single exact term,
+// otherwise every word the analyzer produced is a separate term.
+func terms(tok token, field string, an Analyzer) []Term {
+	if tok.field != "" {
+		field = tok.field
+	}
+	words := an.Analyze(tok.text)
+	if len(words) == 0 {
+		return nil
+	}
+	if tok.quoted {
+		return []Term{{Text: tok.text, Field: field, Words: words, Exact: true}}
+	}
+	if len(words) == 1 {
+		t := Term{Text: tok.text, Field: field, Words: words}
+		if !tok.negated {
+			t.Variants = variants(tok.text, an)
+		}
+		return []Term{t}
+	}
+	res := make([]Term, 0, len(words))
+	for _, w := range words {
+		res = append(res, Term{Text: tok.text, Field: field, Words: []string{w}})
+	}
+	return res
+}
+
+type token struct {
+	text    string
+	field   string
+	quoted  bool
+	negated bool
+}
+
+// lex splits text into tokens. Unterminated quotes last till the end of text.
+// Prefixes before a colon are fields of tokens only when they are filters or
+// description fields of fields.
+func lex(text string, fields FieldWeights) []token {
+	rs := []rune(text)
+	res := []token{}
+	i := 0
+	for i < len(rs) {
+		if unicode.IsSpace(rs[i]) {
+			i++
+			continue
+		}
+
+		tok := token{}
+		if rs[i] == '-' && i+1 < len(rs) && !unicode.IsSpace(rs[i+1]) {
+			tok.negated = true
+			i++
+		}
+
+		start := i
+		for i < len(rs) && !unicode.IsSpace(rs[i]) && rs[i] != '"' {
+			i++
+		}
+		word := string(rs[start:i])
+		if f, v, ok := strings.Cut(word, ":"); ok && isField(f, fields) {
+			tok.field = f
+			word = v
+		}
+
+		if word == "" && i < len(rs) && rs[i] == '"' {
+			i++
+			start = i
+			for i < len(rs) && rs[i] != '"' {
+				i++
+			}
+			tok.text = string(rs[start:i])
+			tok.quoted = true
+			i++
+		} else {
+			tok.text = word
+		}
+		res = append(res, tok)
+	}
+	return res
+}
+
+// isField reports whether s is a query filter or a description field of fields.
+func isField(s string, fields FieldWeights) bool {
+	if s == "board" || s == "type" || s == GeneralField {
+		return true
+	}
+	_, ok := fields[s]
+	return ok
+}
+
+// Analyzer returns the analyzer query words were produced by.
+func (q Query) Analyzer() Analyzer {
+	if q.analyzer == nil {
+		return DefaultAnalyzer
+	}
+	return q.analyzer
+}
+
+// Words returns words of every query clause including words of variants.
+func (q Query) Words() []string {
+	res := []string{}
+	for _, c := range q.Clauses {
+		for _, t := range c {
+			res = append(res, t.Words...)
+			for _, v := range t.Variants {
+				res = append(res, v.Word)
+			}
+		}
+	}
+	return res
+}
+
+// Allows reports whether meme passes query filters and exclusions.
+func (q Query) Allows(meme models.Meme) bool {
+	if len(q.Boards) > 0 && !slices.Contains(q.Boards, meme.BoardID) {
+		return false
+	}
+	if q.MediaType != "" && meme.MediaType() != q.MediaType {
+		return false
+	}
+	if len(q.Excluded) == 0 {
+		return true
+	}
+	dws := fieldWords(meme.BoardID, meme.Description, q.Analyzer())
+	for _, t := range q.Excluded {
+		for f, ws := range dws {
+			if (t.Field == GeneralField || t.Field == f) && exactHits(t.Words, ws) > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Filter returns memes allowed by q.
+func Filter(memes []models.Meme, q Query) []models.Meme {
+	res := make([]models.Meme, 0, len(memes))
+	for _, m := range memes {
+		if q.Allows(m) {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// Text renders clauses and exclusions in websearch_to_tsquery syntax. Terms
+// are rendered as typed, so that PostgreSQL stems them with its own
+// dictionaries. Variants become alternatives, fields and filters are left out.
+func (q Query) Text() string {
+	parts := []string{}
+	add := func(p string) {
+		// words split from a single typed token share its text
+		if len(parts) == 0 || parts[len(parts)-1] != p {
+			parts = append(parts, p)
+		}
+	}
+	for _, c := range q.Clauses {
+		alts := make([]string, 0, len(c))
+		for _, t := range c {
+			alts = append(alts, t.typed())
+			for _, v := range t.Variants {
+				alts = append(alts, v.Text)
+			}
+		}
+		add(strings.Join(alts, " or "))
+	}
+	for _, t := range q.Excluded {
+		add("-" + t.typed())
+	}
+	return strings.Join(parts, " ")
+}
+
+// typed returns the term as it was typed, phrases are quoted.
+func (t Term) typed() string {
+	if t.Exact {
+		return `"` + t.Text + `"`
+	}
+	return t.Text
+}
+
+// exactHits counts occurrences of phrase in words.
+func exactHits(phrase []string, words []string) int {
+	n := 0
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		if slices.Equal(phrase, words[i:i+len(phrase)]) {
+			n += 1
+		}
+	}
+	return n
+}
@@ -0,0 +1,123 @@
+package searchranker
+
+import (
+	"memesearch/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFields are description fields which can be typed in queries.
+var testFields = FieldWeights{GeneralField: 1, "tags": 1, "source": 0.5}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want Query
+	}{
+		{
+			text: "грустный кот",
+			want: Query{Clauses: []Clause{
+				{{Text: "грустный", Field: GeneralField, Words: []string{"грустный"}}},
+				{{Text: "кот", Field: GeneralField, Words: []string{"кот"}}},
+			}},
+		},
+		{
+			text: `"грустный кот" -собака`,
+			want: Query{
+				Clauses:  []Clause{{{Text: "грустный кот", Field: GeneralField, Words: []string{"грустный", "кот"}, Exact: true}}},
+				Excluded: []Term{{Text: "собака", Field: GeneralField, Words: []string{"собака"}}},
+			},
+		},
+		{
+			text: "кот OR tags:пёс",
+			want: Query{Clauses: []Clause{{
+				{Text: "кот", Field: GeneralField, Words: []string{"кот"}},
+				{Text: "пёс", Field: "tags", Words: []string{"пёс"}},
+			}}},
+		},
+		{
+			text: `source:"reddit com" board:42 type:video`,
+			want: Query{
+				Clauses:   []Clause{{{Text: "reddit com", Field: "source", Words: []string{"reddit", "com"}, Exact: true}}},
+				Boards:    []models.BoardID{"42"},
+				MediaType: models.MediaTypeVideo,
+			},
+		},
+		{
+			text: "встреча 12:30",
+			want: Query{Clauses: []Clause{
+				{{Text: "встреча", Field: GeneralField, Words: []string{"встреча"}}},
+				{{Text: "12:30", Field: GeneralField, Words: []string{"12:30"}}},
+			}},
+		},
+		{
+			text: "https://example.com/cat",
+			want: Query{Clauses: []Clause{
+				{{Text: "https://example.com/cat", Field: GeneralField, Words: []string{"https://example.com/cat"}}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Parse(tt.text, GeneralField, SimpleAnalyzer{}, testFields)
+			require.NoError(t, err)
+			for _, c := range got.Clauses {
+				for i := range c {
+					c[i].Variants = nil
+				}
+			}
+			tt.want.analyzer = SimpleAnalyzer{}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, text := range []string{"type:gif", "-board:42", "type:photo type:video"} {
+		_, err := Parse(text, GeneralField, SimpleAnalyzer{}, nil)
+		assert.ErrorIs(t, err, ErrQuerySyntax, text)
+	}
+}
+
+func TestQueryAllows(t *testing.T) {
+	meme := models.Meme{
+		BoardID:     "1",
+		Filename:    "file_1.mp4",
+		Description: map[string]string{"general": "грустный кот сидит", "tags": "кот"},
+	}
+
+	for text, want := range map[string]bool{
+		"кот":             true,
+		"-кот":            false,
+		`-"кот сидит"`:    false,
+		`-"сидит кот"`:    true,
+		"-tags:грустный":  true,
+		"type:photo":      false,
+		"type:video":      true,
+		"board:2":         false,
+		"board:1 board:2": true,
+	} {
+		q, err := Parse(text, GeneralField, SimpleAnalyzer{}, testFields)
+		require.NoError(t, err)
+		assert.Equal(t, want, q.Allows(meme), text)
+	}
+}
+
+func TestQueryText(t *testing.T) {
+	q, err := Parse(`Грустные котики OR dogs кот-пёс -"злая собака" tags:мемы board:1`, GeneralField, StemmingAnalyzer{}, testFields)
+	require.NoError(t, err)
+	for _, c := range q.Clauses {
+		for i := range c {
+			c[i].Variants = nil
+		}
+	}
+	// words are left for PostgreSQL to stem, split words are rendered once
+	assert.Equal(t, `Грустные котики or dogs кот-пёс мемы -"злая собака"`, q.Text())
+
+	// variants are alternatives of the typed word
+	q, err = Parse("rjn", GeneralField, StemmingAnalyzer{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "rjn or кот or рйн", q.Text())
+}
@@ -6,30 +6,105 @@ import (
 	"memesearch/internal/models"
 	"sort"
 	"strings"
+	"unicode"
 )
 
 type ScroredMeme struct {
 	Score float64
 	Meme  models.Meme
+	// Corrections maps query terms which matched only in another keyboard
+	// layout or transliterated to the variant that matched.
+	Corrections map[string]string
+	// Explain holds matches of every query term when explanation was requested.
+	Explain []TermMatch
+	// Ranker is the experiment arm which produced the result, if any.
+	Ranker string
 }
 type Ranker interface {
-	Rank(ctx context.Context, mems []models.Meme, req map[string]string) ([]ScroredMeme, error)
+	Rank(ctx context.Context, mems []models.Meme, req Request) ([]ScroredMeme, error)
+}
+
+// Searcher is implemented by rankers which retrieve candidates themselves
+// instead of ranking memes loaded by the caller.
+type Searcher interface {
+	Search(ctx context.Context, userID models.UserID, req Request) ([]ScroredMeme, error)
+}
+
+// Retriever is implemented by rankers which find candidates themselves in
+// addition to memes sharing words with the query.
+type Retriever interface {
+	Retrieve(ctx context.Context, boards []models.BoardID, req Request) ([]models.Meme, error)
+}
+
+// Merge appends memes of more missing from memes.
+func Merge(memes, more []models.Meme) []models.Meme {
+	seen := make(map[models.MemeID]struct{}, len(memes))
+	for _, m := range memes {
+		seen[m.ID] = struct{}{}
+	}
+	for _, m := range more {
+		if _, ok := seen[m.ID]; !ok {
+			seen[m.ID] = struct{}{}
+			memes = append(memes, m)
+		}
+	}
+	return memes
+}
+
+// Request is a search query together with the context it is ranked in.
+type Request struct {
+	// Query is the parsed search query.
+	Query Query
+	// Stats describes the corpus visible to the user. Rankers which need it
+	// fall back to statistics of the ranked memes when it is nil.
+	Stats CorpusStats
+	// Vocabulary pre-selects description words similar to query words.
+	// Every pair of words is compared when it is nil.
+	Vocabulary Vocabulary
+	// Filter is applied by Searchers while retrieving candidates. Memes
+	// passed to Rank are already filtered.
+	Filter models.MemeFilter
+	// Explain asks rankers to report how every query term matched.
+	// Rankers which can't explain results leave it out.
+	Explain bool
+	// UserID is the user searching. Searchers restrict memes to boards
+	// visible to the user unless Board is set.
+	UserID models.UserID
+	// Board scopes Searchers to memes of the board, whether or not the
+	// user sees it.
+	Board models.BoardID
+	// Synonyms expand query terms with synonyms of the board of every
+	// ranked meme. Searchers may ignore them.
+	Synonyms *Synonyms
+}
+
+// CorpusStats describes the set of memes a search runs over.
+type CorpusStats interface {
+	// DocCount is the number of memes in the corpus.
+	DocCount() int
+	// AvgDocLen is the average number of description words per meme.
+	AvgDocLen() float64
+	// DocFreq is the number of memes whose description contains term.
+	DocFreq(term string) int
 }
 
 var _ Ranker = &DefaultRanker{}
 
 type DefaultRanker struct {
+	// Fields weighs description fields, DefaultFieldWeights is used when empty.
+	Fields FieldWeights
 }
 
 // Rank implements Ranker.
-func (dr *DefaultRanker) Rank(ctx context.Context, memes []models.Meme, req map[string]string) ([]ScroredMeme, error) {
+func (dr *DefaultRanker) Rank(ctx context.Context, memes []models.Meme, req Request) ([]ScroredMeme, error) {
+	matcher := newMatcher(req.Vocabulary)
 	res := make([]ScroredMeme, 0, len(memes))
 	for _, m := range memes {
-		s := dr.score(ctx, m.Description, req)
+		s, corrections, matches := dr.score(ctx, m, req, matcher)
 		if s < 0.01 {
 			continue
 		}
-		res = append(res, ScroredMeme{Score: s, Meme: m})
+		res = append(res, ScroredMeme{Score: s, Meme: m, Corrections: corrections, Explain: matches})
 	}
 	sort.Slice(res, func(i, j int) bool {
 		return res[i].Score > res[j].Score
@@ -38,42 +113,57 @@ func (dr *DefaultRanker) Rank(ctx context.Context, memes []models.Meme, req map[
 
 }
 
-func (dr *DefaultRanker) score(ctx context.Context, dsc map[string]string, req map[string]string) float64 {
-	r, ok := req["general"]
-	if !ok || len(r) == 0 {
-		slog.DebugContext(ctx, "Can't find 'general' field in request")
-		return -1
-	}
-	d, ok := dsc["general"]
-	if !ok || len(r) == 0 {
-		slog.DebugContext(ctx, "Can't find 'general' field in description")
-		return -1
+// poorSimilarity is the similarity up to which variants of a term are tried.
+const poorSimilarity = 0.8
+
+// score averages the best weighted similarity of every query clause.
+// Clauses made of stop words of the meme board are left out.
+func (dr *DefaultRanker) score(ctx context.Context, meme models.Meme, req Request, m *matcher) (float64, map[string]string, []TermMatch) {
+	fields := dr.Fields.orDefault()
+	an := req.Query.Analyzer()
+	dws := fieldWords(meme.BoardID, meme.Description, an)
+
+	clauses := searchedClauses(an, meme.BoardID, req.Synonyms.Expand(meme.BoardID, req.Query.Clauses))
+	if len(clauses) == 0 {
+		slog.DebugContext(ctx, "No words to search in request")
+		return -1, nil, nil
 	}
 
-	rs := strings.Split(strings.ToLower(r), " ")
-	ds := strings.Split(strings.ToLower(d), " ")
-	n := 0
-	totalScore := 0.0
-	for _, i := range rs {
-		if commonlyUsed(i) {
-			continue
-		}
-		n += 1
-		mx := 0.0
-		for _, j := range ds {
-			if commonlyUsed(j) {
-				continue
+	total, corrections, matches := scoreClauses(clauses, poorSimilarity, req.Explain, func(t Term) TermMatch {
+		best := TermMatch{Threshold: termThreshold}
+		for f, w := range fields.Searched(t.Field) {
+			word, sim := termSimilarity(t, dws[f], m)
+			if w*sim > best.Score {
+				best.Field, best.Word, best.Similarity, best.Weight, best.Score = f, word, sim, w, w*sim
 			}
-			score := 1 - normlizedLevenstainDist(i, j)
-			mx = max(mx, score)
 		}
-		if mx > 0.5 {
-			totalScore += mx
+		return best
+	})
+	return total / float64(len(clauses)), corrections, matches
+}
+
+// termSimilarity returns the word most similar to term and their similarity.
+// Exact terms have similarity 1 when found and 0 otherwise.
+func termSimilarity(t Term, words []string, m *matcher) (string, float64) {
+	if t.Exact {
+		if exactHits(t.Words, words) > 0 {
+			return strings.Join(t.Words, " "), 1
+		}
+		return "", 0
+	}
+	word, mx := "", 0.0
+	for _, j := range words {
+		if s := m.similarity(t.Words[0], j); s > mx {
+			word, mx = j, s
 		}
 	}
-	return totalScore / float64(n)
+	return word, mx
 }
 
+// termThreshold is the similarity a description word must exceed
+// to count as a hit for a query word.
+const termThreshold = 0.5
+
 func normlizedLevenstainDist(as, bs string) float64 {
 	a := []rune(as)
 	b := []rune(bs)
@@ -123,6 +213,10 @@ func cost(a, b rune) float64 {
 	if !ok {
 		return 100
 	}
+	// typing in the other layout is handled by query variants
+	if unicode.Is(unicode.Latin, a) != unicode.Is(unicode.Latin, b) {
+		return 2
+	}
 	dst := abs(pa.x-pb.x) + abs(pa.y-pb.y)
 
 	switch dst {
@@ -143,14 +237,3 @@ func abs(i int) int {
 	}
 	return -i
 }
-
-// var commonly map[string]struct{} = map[string]struct{}{
-// 	"или": struct{}{},
-// }
-
-func commonlyUsed(a string) bool {
-	if len([]rune(a)) <= 2 {
-		return true
-	}
-	return false
-}
@@ -0,0 +1,54 @@
+package searchranker
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Registry holds constructors of named rankers, so that rankers are
+// selected by name in config.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]func() (Ranker, error)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{factories: map[string]func() (Ranker, error){}}
+}
+
+// Register adds a ranker constructor replacing the one with the same name.
+func (r *Registry) Register(name string, f func() (Ranker, error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.factories[name] = f
+}
+
+// New creates the ranker registered as name.
+func (r *Registry) New(name string) (Ranker, error) {
+	r.mu.RLock()
+	f, ok := r.factories[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown ranker %q, registered are %v", name, r.Names())
+	}
+	ranker, err := f()
+	if err != nil {
+		return nil, fmt.Errorf("can't create ranker %q: %w", name, err)
+	}
+	return ranker, nil
+}
+
+// Names returns sorted names of registered rankers.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make([]string, 0, len(r.factories))
+	for n := range r.factories {
+		res = append(res, n)
+	}
+	slices.Sort(res)
+	return res
+}
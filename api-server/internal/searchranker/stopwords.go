@@ -0,0 +1,163 @@
+package searchranker
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"memesearch/internal/models"
+	"os"
+	"slices"
+	"strings"
+)
+
+//go:embed stopwords/*.txt
+var defaultStopWords embed.FS
+
+// StopWords holds words which take no part in matching. Words are kept
+// analyzed so that every form of a stop word is dropped.
+type StopWords struct {
+	global map[string]struct{}
+	boards map[models.BoardID]map[string]struct{}
+}
+
+// NewStopWords analyzes words and per-board extra words with an.
+func NewStopWords(an Analyzer, words []string, boards map[models.BoardID][]string) *StopWords {
+	sw := &StopWords{
+		global: analyzedSet(an, words),
+		boards: make(map[models.BoardID]map[string]struct{}, len(boards)),
+	}
+	for b, ws := range boards {
+		sw.boards[b] = analyzedSet(an, ws)
+	}
+	return sw
+}
+
+func analyzedSet(an Analyzer, words []string) map[string]struct{} {
+	res := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		for _, t := range an.Analyze(w) {
+			res[t] = struct{}{}
+		}
+	}
+	return res
+}
+
+// Contains reports whether term is a stop word on board. Empty board
+// checks global stop words only.
+func (sw *StopWords) Contains(board models.BoardID, term string) bool {
+	if sw == nil {
+		return false
+	}
+	if _, ok := sw.global[term]; ok {
+		return true
+	}
+	_, ok := sw.boards[board][term]
+	return ok
+}
+
+// LoadStopWords reads default lists of languages and lists from files.
+func LoadStopWords(languages []string, files []string) ([]string, error) {
+	res := []string{}
+	for _, lang := range languages {
+		f, err := defaultStopWords.Open("stopwords/" + lang + ".txt")
+		if err != nil {
+			return nil, fmt.Errorf("no stop words for language %s: %w", lang, err)
+		}
+		res = append(res, readStopWords(f)...)
+		f.Close()
+	}
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("can't open stop words file: %w", err)
+		}
+		res = append(res, readStopWords(f)...)
+		f.Close()
+	}
+	return res, nil
+}
+
+// readStopWords reads a word per line skipping blank lines and # comments.
+func readStopWords(r io.Reader) []string {
+	res := []string{}
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		w := strings.TrimSpace(s.Text())
+		if w == "" || strings.HasPrefix(w, "#") {
+			continue
+		}
+		res = append(res, w)
+	}
+	return res
+}
+
+var _ Analyzer = StopFilter{}
+
+// StopFilter drops stop words from terms of the wrapped analyzer.
+type StopFilter struct {
+	Analyzer
+	Stop *StopWords
+}
+
+// Analyze implements Analyzer dropping global stop words.
+func (sf StopFilter) Analyze(text string) []string {
+	return sf.AnalyzeBoard("", text)
+}
+
+// AnalyzeBoard analyzes text of a meme on board dropping global and board stop words.
+func (sf StopFilter) AnalyzeBoard(board models.BoardID, text string) []string {
+	terms := sf.Analyzer.Analyze(text)
+	res := terms[:0]
+	for _, t := range terms {
+		if !sf.Stop.Contains(board, t) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// Stopped reports whether term is a stop word on board.
+func (sf StopFilter) Stopped(board models.BoardID, term string) bool {
+	return sf.Stop.Contains(board, term)
+}
+
+// boardAnalyzer is implemented by analyzers which know board stop words.
+type boardAnalyzer interface {
+	AnalyzeBoard(board models.BoardID, text string) []string
+	Stopped(board models.BoardID, term string) bool
+}
+
+// analyzeBoard analyzes text of a meme on board.
+func analyzeBoard(an Analyzer, board models.BoardID, text string) []string {
+	if ba, ok := an.(boardAnalyzer); ok {
+		return ba.AnalyzeBoard(board, text)
+	}
+	return an.Analyze(text)
+}
+
+// stopped reports whether every word of t is a stop word on board.
+func stopped(an Analyzer, board models.BoardID, t Term) bool {
+	ba, ok := an.(boardAnalyzer)
+	if !ok {
+		return false
+	}
+	for _, w := range t.Words {
+		if !ba.Stopped(board, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// searchedClauses leaves out clauses whose every term is made of stop words
+// of board.
+func searchedClauses(an Analyzer, board models.BoardID, clauses []Clause) []Clause {
+	res := make([]Clause, 0, len(clauses))
+	for _, c := range clauses {
+		if slices.ContainsFunc(c, func(t Term) bool { return !stopped(an, board, t) }) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
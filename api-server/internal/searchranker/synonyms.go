@@ -0,0 +1,83 @@
+package searchranker
+
+import (
+	"memesearch/internal/models"
+	"slices"
+	"strings"
+)
+
+// Synonyms holds synonym groups of boards. Query terms matching a member
+// of a group are expanded with the other members when memes of the board
+// are ranked. A nil Synonyms expands nothing.
+type Synonyms struct {
+	// boards maps analyzed group members to the other members of their groups.
+	boards map[models.BoardID]map[string][]Term
+}
+
+// NewSynonyms analyzes groups of every board with an.
+func NewSynonyms(an Analyzer, groups map[models.BoardID][][]string) *Synonyms {
+	s := &Synonyms{boards: map[models.BoardID]map[string][]Term{}}
+	for b, gs := range groups {
+		terms := map[string][]Term{}
+		for _, g := range gs {
+			members := make([]Term, 0, len(g))
+			for _, text := range g {
+				words := analyzeBoard(an, b, text)
+				if len(words) == 0 {
+					continue
+				}
+				members = append(members, Term{Text: text, Words: words, Exact: len(words) > 1})
+			}
+			for i, m := range members {
+				key := strings.Join(m.Words, " ")
+				for j, o := range members {
+					if i != j && strings.Join(o.Words, " ") != key {
+						terms[key] = append(terms[key], o)
+					}
+				}
+			}
+		}
+		if len(terms) > 0 {
+			s.boards[b] = terms
+		}
+	}
+	return s
+}
+
+// Expand returns clauses with synonyms on board added to every term
+// as alternatives searched in the same field.
+func (s *Synonyms) Expand(board models.BoardID, clauses []Clause) []Clause {
+	if s == nil || len(s.boards[board]) == 0 {
+		return clauses
+	}
+	terms := s.boards[board]
+	res := make([]Clause, 0, len(clauses))
+	for _, c := range clauses {
+		expanded := slices.Clone(c)
+		for _, t := range c {
+			for _, syn := range terms[strings.Join(t.Words, " ")] {
+				syn.Field = t.Field
+				expanded = append(expanded, syn)
+			}
+		}
+		res = append(res, expanded)
+	}
+	return res
+}
+
+// Words returns analyzed synonyms on boards of words, so that memes
+// containing only synonyms of query words are ranked too.
+func (s *Synonyms) Words(boards []models.BoardID, words []string) []string {
+	if s == nil {
+		return nil
+	}
+	res := []string{}
+	for _, b := range boards {
+		for _, w := range words {
+			for _, syn := range s.boards[b][w] {
+				res = append(res, syn.Words...)
+			}
+		}
+	}
+	return res
+}
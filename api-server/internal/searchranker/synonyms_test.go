@@ -0,0 +1,40 @@
+package searchranker
+
+import (
+	"context"
+	"memesearch/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSynonyms(t *testing.T) {
+	memes := []models.Meme{
+		{ID: "1", BoardID: "a", Description: map[string]string{"general": "cat sleeps"}},
+		{ID: "2", BoardID: "b", Description: map[string]string{"general": "cat sleeps"}},
+		{ID: "3", BoardID: "a", Description: map[string]string{"general": "толстый кот"}},
+	}
+	syn := NewSynonyms(DefaultAnalyzer, map[models.BoardID][][]string{
+		"a": {{"кот", "cat"}, {"жиробас", "толстый кот"}},
+	})
+
+	q, err := Parse("кот", GeneralField, DefaultAnalyzer, nil)
+	require.NoError(t, err)
+	res, err := (&DefaultRanker{}).Rank(context.Background(), memes, Request{Query: q, Synonyms: syn})
+	require.NoError(t, err)
+	ids := []models.MemeID{}
+	for _, m := range res {
+		ids = append(ids, m.Meme.ID)
+	}
+	assert.ElementsMatch(t, []models.MemeID{"1", "3"}, ids)
+
+	q, err = Parse("жиробас", GeneralField, DefaultAnalyzer, nil)
+	require.NoError(t, err)
+	res, err = (&DefaultRanker{}).Rank(context.Background(), memes, Request{Query: q, Synonyms: syn})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, models.MemeID("3"), res[0].Meme.ID)
+
+	assert.Equal(t, []string{"cat"}, syn.Words([]models.BoardID{"a", "b"}, []string{"кот"}))
+}
@@ -0,0 +1,142 @@
+package searchranker
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Variant is an alternative spelling of a query term, e.g. "кот" for "rjn"
+// typed in the wrong keyboard layout or "kot" written in Latin.
+type Variant struct {
+	// Text is the corrected query text.
+	Text string
+	// Word is the analyzed Text.
+	Word string
+}
+
+// variantPenalty discounts scores of variants so that memes matching
+// the query as typed rank higher.
+const variantPenalty = 0.9
+
+// variants returns layout swapped and transliterated spellings of text
+// which differ from it.
+func variants(text string, an Analyzer) []Variant {
+	text = strings.ToLower(text)
+	res := []Variant{}
+	seen := map[string]struct{}{text: {}}
+	for _, v := range []string{swapLayout(text), transliterate(text)} {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		words := an.Analyze(v)
+		if len(words) != 1 {
+			continue
+		}
+		res = append(res, Variant{Text: v, Word: words[0]})
+	}
+	return res
+}
+
+// withVariants scores t with score and, when the result doesn't exceed poor,
+// scores its variants as well. Variant scores are penalized and the match
+// of a variant records the variant text.
+func withVariants(t Term, poor float64, score func(t Term) TermMatch) TermMatch {
+	best := score(t)
+	best.Term = t.Text
+	if best.Score > poor {
+		return best
+	}
+	for _, v := range t.Variants {
+		tm := score(Term{Text: v.Text, Field: t.Field, Words: []string{v.Word}})
+		tm.Score *= variantPenalty
+		if tm.Score > best.Score {
+			tm.Term, tm.Variant = t.Text, v.Text
+			best = tm
+		}
+	}
+	return best
+}
+
+// layoutSwap maps keys of the QWERTY layout to the keys of ЙЦУКЕН
+// at the same position and vice versa.
+var layoutSwap = func() map[rune]rune {
+	res := map[rune]rune{}
+	pos := map[struct{ x, y int }]rune{}
+	for r, p := range keyboardLayout {
+		if unicode.Is(unicode.Latin, r) {
+			pos[p] = r
+		}
+	}
+	for r, p := range keyboardLayout {
+		if l, ok := pos[p]; ok && unicode.Is(unicode.Cyrillic, r) {
+			res[l] = r
+			res[r] = l
+		}
+	}
+	// punctuation keys of QWERTY hold letters in ЙЦУКЕН
+	for l, r := range map[rune]rune{'[': 'х', ']': 'ъ', ';': 'ж', '\'': 'э', ',': 'б', '.': 'ю', '`': 'ё'} {
+		res[l] = r
+		res[r] = l
+	}
+	return res
+}()
+
+func swapLayout(text string) string {
+	return strings.Map(func(r rune) rune {
+		if s, ok := layoutSwap[r]; ok {
+			return s
+		}
+		return r
+	}, text)
+}
+
+// latinDigraphs are matched before single latin letters.
+var latinDigraphs = []struct{ lat, cyr string }{
+	{"shch", "щ"}, {"sch", "щ"}, {"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"ch", "ч"},
+	{"sh", "ш"}, {"yu", "ю"}, {"ya", "я"}, {"yo", "ё"}, {"ye", "е"},
+}
+
+var latinToCyrillic = map[rune]string{
+	'a': "а", 'b': "б", 'c': "ц", 'd': "д", 'e': "е", 'f': "ф", 'g': "г", 'h': "х", 'i': "и",
+	'j': "й", 'k': "к", 'l': "л", 'm': "м", 'n': "н", 'o': "о", 'p': "п", 'q': "к", 'r': "р",
+	's': "с", 't': "т", 'u': "у", 'v': "в", 'w': "в", 'x': "кс", 'y': "ы", 'z': "з",
+}
+
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// transliterate writes latin text in cyrillic and cyrillic text in latin.
+func transliterate(text string) string {
+	var b strings.Builder
+	for len(text) > 0 {
+		if cyr, rest, ok := cutDigraph(text); ok {
+			b.WriteString(cyr)
+			text = rest
+			continue
+		}
+		r := []rune(text)[0]
+		text = text[len(string(r)):]
+		if s, ok := latinToCyrillic[r]; ok {
+			b.WriteString(s)
+		} else if s, ok := cyrillicToLatin[r]; ok {
+			b.WriteString(s)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func cutDigraph(text string) (string, string, bool) {
+	for _, d := range latinDigraphs {
+		if rest, ok := strings.CutPrefix(text, d.lat); ok {
+			return d.cyr, rest, true
+		}
+	}
+	return "", text, false
+}
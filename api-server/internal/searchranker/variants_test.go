@@ -0,0 +1,39 @@
+package searchranker
+
+import (
+	"context"
+	"memesearch/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariants(t *testing.T) {
+	assert.Equal(t, "кот", swapLayout("rjn"))
+	assert.Equal(t, "ежик", swapLayout("t;br"))
+	assert.Equal(t, "кот", transliterate("kot"))
+	assert.Equal(t, "щука", transliterate("shchuka"))
+	assert.Equal(t, "koshka", transliterate("кошка"))
+}
+
+func TestRankCorrections(t *testing.T) {
+	memes := []models.Meme{
+		{ID: "1", Description: map[string]string{"general": "грустный кот"}},
+		{ID: "2", Description: map[string]string{"general": "весёлая собака"}},
+	}
+	for _, r := range []Ranker{&DefaultRanker{}, NewBM25Ranker()} {
+		for text, want := range map[string]string{"rjn": "кот", "sobaka": "собака"} {
+			q, err := Parse(text, GeneralField, DefaultAnalyzer, nil)
+			require.NoError(t, err)
+			res, err := r.Rank(context.Background(), memes, Request{Query: q, Explain: true})
+			require.NoError(t, err)
+			require.Len(t, res, 1, text)
+			assert.Equal(t, map[string]string{text: want}, res[0].Corrections)
+			require.Len(t, res[0].Explain, 1)
+			assert.True(t, res[0].Explain[0].Used)
+			assert.Equal(t, want, res[0].Explain[0].Variant)
+			assert.Equal(t, GeneralField, res[0].Explain[0].Field)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Package searchsetup creates search components chosen in config. It is
+// shared by the API server and offline tools, so that both search alike.
+package searchsetup
+
+import (
+	"context"
+	"fmt"
+	"memesearch/internal/config"
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+	"memesearch/internal/storage"
+	"memesearch/internal/storage/psql"
+	"memesearch/internal/vectorindex"
+	"time"
+)
+
+// NewAnalyzer creates the analyzer chosen in config which drops configured stop words.
+func NewAnalyzer(cfg config.SearchConfig) (searchranker.Analyzer, error) {
+	analyzer, err := searchranker.NewAnalyzer(cfg.Analyzer)
+	if err != nil {
+		return nil, err
+	}
+	words, err := searchranker.LoadStopWords(cfg.StopWords.Languages, cfg.StopWords.Files)
+	if err != nil {
+		return nil, fmt.Errorf("can't load stop words: %w", err)
+	}
+	words = append(words, cfg.StopWords.Words...)
+	boards := make(map[models.BoardID][]string, len(cfg.StopWords.Boards))
+	for b, ws := range cfg.StopWords.Boards {
+		boards[models.BoardID(b)] = ws
+	}
+	return searchranker.StopFilter{
+		Analyzer: analyzer,
+		Stop:     searchranker.NewStopWords(analyzer, words, boards),
+	}, nil
+}
+
+// NewRegistry registers every ranker available in config. Rankers keeping
+// their own index of memes wrap s.MemeRepo to follow changes.
+func NewRegistry(cfg config.Config, s *storage.Storage, analyzer searchranker.Analyzer) *searchranker.Registry {
+	registry := searchranker.NewRegistry()
+	registry.Register("default", func() (searchranker.Ranker, error) {
+		return &searchranker.DefaultRanker{Fields: cfg.Search.FieldWeights}, nil
+	})
+	registry.Register("bm25", func() (searchranker.Ranker, error) {
+		ranker := searchranker.NewBM25Ranker()
+		ranker.Fields = cfg.Search.FieldWeights
+		return ranker, nil
+	})
+	registry.Register("fts", func() (searchranker.Ranker, error) {
+		return psql.NewFTSRanker(cfg.Database)
+	})
+	registry.Register("semantic", func() (searchranker.Ranker, error) {
+		return newSemanticRanker(cfg, s, analyzer)
+	})
+	return registry
+}
+
+// newSemanticRanker opens the persisted vector index and brings it in line
+// with memes of s.
+func newSemanticRanker(cfg config.Config, s *storage.Storage, analyzer searchranker.Analyzer) (searchranker.Ranker, error) {
+	sc := cfg.Search.Semantic
+	index, err := vectorindex.Open(sc.Path, analyzer, vectorindex.NewNGramEmbedder(sc.Dim))
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	err = index.Sync(ctx, s.MemeRepo)
+	if err != nil {
+		return nil, fmt.Errorf("can't sync vector index: %w", err)
+	}
+	s.MemeRepo = vectorindex.NewMemeRepo(s.MemeRepo, index)
+	if sc.Path != "" {
+		if err := index.Save(sc.Path); err != nil {
+			return nil, fmt.Errorf("can't save vector index: %w", err)
+		}
+		go index.Persist(ctx, sc.Path, time.Minute)
+	}
+
+	return &vectorindex.Ranker{
+		Index:      index,
+		Neighbours: sc.Neighbours,
+		Fuzzy:      &searchranker.DefaultRanker{Fields: cfg.Search.FieldWeights},
+		Blend:      sc.Blend,
+		MinScore:   sc.MinScore,
+	}, nil
+}
@@ -56,7 +56,7 @@ func (b *BoardStore) CreateBoard(ctx context.Context, owner models.UserID, name
 
 // UpdateBoard implements models.BoardRepo.
 func (b *BoardStore) UpdateBoard(ctx context.Context, board models.Board) error {
-	res, err := b.db.Exec("UPDATE boards SET owner_id = $2, name = $3 WHERE id=$1", board.ID, board.Owner, board.Name)
+	res, err := b.db.Exec("UPDATE boards SET owner_id = $2, name = $3, settings = $4 WHERE id=$1", board.ID, board.Owner, board.Name, board.Settings)
 	if err != nil {
 		return fmt.Errorf("can't update: %w", err)
 	}
@@ -93,7 +93,7 @@ func (b *BoardStore) ListBoards(ctx context.Context, userID models.UserID, offse
 	SELECT board_id AS id FROM subscriptions WHERE user_id=$1
 	UNION
 	SELECT id FROM boards WHERE owner_id=$1
-	)  OFFSET $2 LIMIT $3`, userID, offset, limit)
+	) ORDER BY id OFFSET $2 LIMIT $3`, userID, offset, limit)
 	if err != nil {
 		return nil, fmt.Errorf("can't select: %w", err)
 
@@ -0,0 +1,46 @@
+package psql
+
+import (
+	"fmt"
+	"memesearch/internal/models"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// memeFilterSQL renders filter as conditions on the memes table. Every
+// condition is prefixed with AND and its arguments are appended to args,
+// so placeholders continue the numbering of args.
+func memeFilterSQL(filter models.MemeFilter, args []any) (string, []any) {
+	var b strings.Builder
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(filter.Boards) > 0 {
+		ids := make([]string, 0, len(filter.Boards))
+		for _, id := range filter.Boards {
+			ids = append(ids, string(id))
+		}
+		b.WriteString(" AND board_id = ANY(" + arg(pq.Array(ids)) + ")")
+	}
+	if filter.MediaType != "" {
+		exts := []string{}
+		for _, e := range models.MediaTypeExtensions[filter.MediaType] {
+			exts = append(exts, regexp.QuoteMeta(e))
+		}
+		b.WriteString(" AND lower(filename) ~ " + arg("("+strings.Join(exts, "|")+")$"))
+	}
+	if !filter.CreatedAfter.IsZero() {
+		b.WriteString(" AND created_at > " + arg(filter.CreatedAfter))
+	}
+	if !filter.CreatedBefore.IsZero() {
+		b.WriteString(" AND created_at < " + arg(filter.CreatedBefore))
+	}
+	if filter.Owner != "" {
+		b.WriteString(" AND board_id IN (SELECT id FROM boards WHERE owner_id=" + arg(filter.Owner) + ")")
+	}
+	return b.String(), args
+}
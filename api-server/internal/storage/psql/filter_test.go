@@ -0,0 +1,48 @@
+package psql
+
+import (
+	"memesearch/internal/models"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemeFilterSQL(t *testing.T) {
+	after := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := after.Add(24 * time.Hour)
+
+	t.Run("Empty", func(t *testing.T) {
+		conds, args := memeFilterSQL(models.MemeFilter{}, []any{"user"})
+		assert.Empty(t, conds)
+		assert.Equal(t, []any{"user"}, args)
+	})
+
+	t.Run("Numbering continues args", func(t *testing.T) {
+		filter := models.MemeFilter{
+			Boards:        []models.BoardID{"a", "b"},
+			MediaType:     models.MediaTypeVideo,
+			CreatedAfter:  after,
+			CreatedBefore: before,
+			Owner:         "owner",
+		}
+		conds, args := memeFilterSQL(filter, []any{"user", "query"})
+		assert.Equal(t, " AND board_id = ANY($3)"+
+			" AND lower(filename) ~ $4"+
+			" AND created_at > $5"+
+			" AND created_at < $6"+
+			" AND board_id IN (SELECT id FROM boards WHERE owner_id=$7)", conds)
+		assert.Len(t, args, 7)
+		assert.Equal(t, []any{"user", "query"}, args[:2])
+		assert.Equal(t, pq.Array([]string{"a", "b"}), args[2])
+		assert.Equal(t, `(\.mp4|\.mov|\.webm)$`, args[3])
+		assert.Equal(t, []any{after, before, models.UserID("owner")}, args[4:])
+	})
+
+	t.Run("Sparse filter", func(t *testing.T) {
+		conds, args := memeFilterSQL(models.MemeFilter{CreatedBefore: before}, []any{"user", 0, 10})
+		assert.Equal(t, " AND created_at < $4", conds)
+		assert.Equal(t, []any{"user", 0, 10, before}, args)
+	})
+}
@@ -0,0 +1,99 @@
+package psql
+
+import (
+	"context"
+	"fmt"
+	"memesearch/internal/config"
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var _ searchranker.Ranker = &FTSRanker{}
+var _ searchranker.Searcher = &FTSRanker{}
+
+// FTSRanker ranks memes with PostgreSQL full-text search over
+// the generated memes.search_vector column.
+type FTSRanker struct {
+	db *sqlx.DB
+}
+
+func NewFTSRanker(cfg config.DatabaseConfig) (*FTSRanker, error) {
+	db, err := connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &FTSRanker{db: db}, nil
+}
+
+type psqlScoredMeme struct {
+	psqlMeme
+	Score float64 `db:"score"`
+}
+
+// ftsQuery matches $2 against both russian and english configurations.
+const ftsQuery = `WITH q AS (
+	SELECT websearch_to_tsquery('russian', $2) || websearch_to_tsquery('english', $2) AS query
+)`
+
+// Rank implements searchranker.Ranker.
+func (r *FTSRanker) Rank(ctx context.Context, memes []models.Meme, req searchranker.Request) ([]searchranker.ScroredMeme, error) {
+	ids := make([]string, 0, len(memes))
+	for _, m := range memes {
+		ids = append(ids, string(m.ID))
+	}
+	var mps []psqlScoredMeme
+	err := r.db.SelectContext(ctx, &mps, ftsQuery+`
+	SELECT `+memeColumns+`, ts_rank_cd(search_vector, query) AS score FROM memes, q
+	WHERE search_vector @@ query AND id = ANY($1)
+	ORDER BY score DESC, id`, pq.Array(ids), req.Query.Text())
+	if err != nil {
+		return nil, fmt.Errorf("can't select: %w", err)
+	}
+	return convertPsqlScoredMemes(mps, req.Query)
+}
+
+// Search implements searchranker.Searcher.
+func (r *FTSRanker) Search(ctx context.Context, userID models.UserID, req searchranker.Request) ([]searchranker.ScroredMeme, error) {
+	query, args := ftsSearchSQL(userID, req)
+	var mps []psqlScoredMeme
+	err := r.db.SelectContext(ctx, &mps, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("can't select: %w", err)
+	}
+	return convertPsqlScoredMemes(mps, req.Query)
+}
+
+// ftsSearchSQL renders the query of Search. Memes of req.Board are searched
+// when it is set, memes of boards visible to userID otherwise.
+func ftsSearchSQL(userID models.UserID, req searchranker.Request) (string, []any) {
+	scope, first := "board_id IN ("+visibleBoardsQuery+")", any(userID)
+	if req.Board != "" {
+		scope, first = "board_id = $1", any(req.Board)
+	}
+	conds, args := memeFilterSQL(req.Filter, []any{first, req.Query.Text()})
+	return ftsQuery + `
+	SELECT ` + memeColumns + `, ts_rank_cd(search_vector, query) AS score FROM memes, q
+	WHERE search_vector @@ query AND ` + scope + conds + `
+	ORDER BY score DESC, id`, args
+}
+
+// convertPsqlScoredMemes converts rows dropping memes rejected by query filters.
+// Full-text search doesn't distinguish description fields, so field scoped
+// exclusions are checked here as well.
+func convertPsqlScoredMemes(mps []psqlScoredMeme, query searchranker.Query) ([]searchranker.ScroredMeme, error) {
+	res := make([]searchranker.ScroredMeme, 0, len(mps))
+	for _, mp := range mps {
+		meme, err := convertPsqlMeme(mp.psqlMeme)
+		if err != nil {
+			return nil, fmt.Errorf("can't convert: %w", err)
+		}
+		if !query.Allows(meme) {
+			continue
+		}
+		res = append(res, searchranker.ScroredMeme{Score: mp.Score, Meme: meme})
+	}
+	return res, nil
+}
@@ -0,0 +1,32 @@
+package psql
+
+import (
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFTSSearchSQL(t *testing.T) {
+	q, err := searchranker.Parse("грустный кот", searchranker.GeneralField, searchranker.DefaultAnalyzer, nil)
+	require.NoError(t, err)
+	// PostgreSQL stems words as typed
+	require.Contains(t, q.Text(), "грустный")
+
+	t.Run("Visible boards", func(t *testing.T) {
+		query, args := ftsSearchSQL("user", searchranker.Request{Query: q})
+		assert.Contains(t, query, "board_id IN ("+visibleBoardsQuery+")")
+		assert.Equal(t, []any{models.UserID("user"), q.Text()}, args)
+	})
+
+	t.Run("Board scope", func(t *testing.T) {
+		req := searchranker.Request{Query: q, Board: "board", Filter: models.MemeFilter{Owner: "owner"}}
+		query, args := ftsSearchSQL("user", req)
+		assert.NotContains(t, query, visibleBoardsQuery)
+		assert.True(t, strings.Contains(query, "board_id = $1 AND board_id IN (SELECT id FROM boards WHERE owner_id=$3)"), query)
+		assert.Equal(t, []any{models.BoardID("board"), q.Text(), models.UserID("owner")}, args)
+	})
+}
@@ -0,0 +1,101 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"memesearch/internal/config"
+	"memesearch/internal/imagehash"
+	"memesearch/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var _ models.MediaHashRepo = &MediaHashStore{}
+
+type MediaHashStore struct {
+	db *sqlx.DB
+}
+
+func NewMediaHashStore(cfg config.DatabaseConfig) (*MediaHashStore, error) {
+	db, err := connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &MediaHashStore{db: db}, nil
+}
+
+// hashes are unsigned while postgres BIGINT is signed, so they are stored
+// reinterpreted as int64.
+type psqlMediaHash struct {
+	ID     models.MediaID `db:"id"`
+	SHA256 string         `db:"sha256"`
+	DHash  sql.NullInt64  `db:"dhash"`
+	PHash  sql.NullInt64  `db:"phash"`
+	Forced bool           `db:"forced"`
+}
+
+// uniqueViolation is the SQLSTATE of a unique index violation.
+const uniqueViolation = "23505"
+
+// SetMediaHash implements models.MediaHashRepo.
+func (m *MediaHashStore) SetMediaHash(ctx context.Context, hash models.MediaHash) error {
+	mh := psqlMediaHash{ID: hash.ID, SHA256: hash.SHA256, Forced: hash.Forced}
+	if hash.Hashes != nil {
+		mh.DHash = sql.NullInt64{Int64: int64(hash.Hashes.DHash), Valid: true}
+		mh.PHash = sql.NullInt64{Int64: int64(hash.Hashes.PHash), Valid: true}
+	}
+	// the board of the meme makes the unique index per board
+	_, err := m.db.NamedExecContext(ctx, `INSERT INTO media_hashes (id, board_id, sha256, dhash, phash, forced)
+	VALUES (:id, (SELECT board_id FROM memes WHERE id = :id), :sha256, :dhash, :phash, :forced)
+	ON CONFLICT (id) DO UPDATE SET board_id=EXCLUDED.board_id, sha256=:sha256, dhash=:dhash, phash=:phash, forced=:forced`, mh)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return models.ErrMediaHashExists
+	}
+	if err != nil {
+		return fmt.Errorf("can't insert: %w", err)
+	}
+	return nil
+}
+
+// ListMediaHashes implements models.MediaHashRepo.
+func (m *MediaHashStore) ListMediaHashes(ctx context.Context, boards []models.BoardID) ([]models.MediaHash, error) {
+	ids := make([]string, 0, len(boards))
+	for _, b := range boards {
+		ids = append(ids, string(b))
+	}
+
+	var mhs []psqlMediaHash
+	err := m.db.SelectContext(ctx, &mhs, `SELECT h.id, h.sha256, h.dhash, h.phash FROM media_hashes h
+	JOIN memes ON memes.id = h.id
+	WHERE memes.board_id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("can't select: %w", err)
+	}
+
+	res := make([]models.MediaHash, 0, len(mhs))
+	for _, mh := range mhs {
+		h := models.MediaHash{ID: mh.ID, SHA256: mh.SHA256}
+		if mh.DHash.Valid && mh.PHash.Valid {
+			h.Hashes = &imagehash.Hashes{DHash: imagehash.Hash(mh.DHash.Int64), PHash: imagehash.Hash(mh.PHash.Int64)}
+		}
+		res = append(res, h)
+	}
+	return res, nil
+}
+
+// ListUnhashedMedia implements models.MediaHashRepo.
+func (m *MediaHashStore) ListUnhashedMedia(ctx context.Context, after models.MediaID, limit int) ([]models.MediaID, error) {
+	ids := []models.MediaID{}
+	err := m.db.SelectContext(ctx, &ids, `SELECT memes.id FROM memes
+	LEFT JOIN media_hashes h ON h.id = memes.id
+	WHERE h.id IS NULL AND memes.id > $1
+	ORDER BY memes.id LIMIT $2`, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("can't select: %w", err)
+	}
+	return ids, nil
+}
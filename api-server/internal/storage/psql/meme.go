@@ -13,6 +13,17 @@ import (
 
 var _ models.MemeRepo = &MemeStore{}
 
+// memeColumns lists columns scanned into psqlMeme. Generated columns
+// like search_vector are left out.
+const memeColumns = "id, board_id, filename, descriptions, created_at, updated_at"
+
+// visibleBoardsQuery selects boards user $1 is subscribed to or owns.
+const visibleBoardsQuery = `
+	SELECT board_id AS id FROM subscriptions WHERE user_id=$1
+	UNION
+	SELECT id FROM boards WHERE owner_id=$1
+`
+
 type MemeStore struct {
 	db *sqlx.DB
 }
@@ -43,7 +54,7 @@ func (m *MemeStore) InsertMeme(ctx context.Context, meme models.Meme) (models.Me
 // GetMemeByID implements models.MemeRepo.
 func (m *MemeStore) GetMemeByID(ctx context.Context, id models.MemeID) (models.Meme, error) {
 	var mp psqlMeme
-	err := m.db.Get(&mp, "SELECT * FROM memes WHERE id=$1", id)
+	err := m.db.Get(&mp, "SELECT "+memeColumns+" FROM memes WHERE id=$1", id)
 	if err != nil {
 		switch {
 		case err == sql.ErrNoRows:
@@ -63,7 +74,7 @@ func (m *MemeStore) GetMemeByID(ctx context.Context, id models.MemeID) (models.M
 // GetMemesByBoardID implements models.MemeRepo.
 func (m *MemeStore) GetMemesByBoardID(ctx context.Context, id models.BoardID, offset int, limit int) ([]models.Meme, error) {
 	var mps []psqlMeme
-	err := m.db.Select(&mps, "SELECT * FROM memes WHERE board_id=$1 ORDER BY id OFFSET $2 LIMIT $3", id, offset, limit)
+	err := m.db.Select(&mps, "SELECT "+memeColumns+" FROM memes WHERE board_id=$1 ORDER BY id OFFSET $2 LIMIT $3", id, offset, limit)
 	if err != nil {
 		return []models.Meme{}, fmt.Errorf("can't select: %w", err)
 	}
@@ -115,14 +126,29 @@ func (m *MemeStore) DeleteMeme(ctx context.Context, id models.MemeID) error {
 	return nil
 }
 
-func (m *MemeStore) ListMemes(ctx context.Context, userID models.UserID, offset, limit int, sortBy string) ([]models.Meme, error) {
-	// TODO use userid
+func (m *MemeStore) ListMemes(ctx context.Context, userID models.UserID, filter models.MemeFilter, offset, limit int, sortBy string) ([]models.Meme, error) {
+	conds, args := memeFilterSQL(filter, []any{userID, offset, limit})
+	var mps []psqlMeme
+	err := m.db.Select(&mps, "SELECT "+memeColumns+" FROM memes WHERE board_id IN ("+visibleBoardsQuery+")"+conds+" ORDER BY id OFFSET $2 LIMIT $3", args...)
+	if err != nil {
+		return []models.Meme{}, fmt.Errorf("can't select: %w", err)
+	}
+	memes := make([]models.Meme, 0, len(mps))
+	for _, mp := range mps {
+		meme, err := convertPsqlMeme(mp)
+		if err != nil {
+			return []models.Meme{}, fmt.Errorf("can't convert: %w", err)
+		}
+		memes = append(memes, meme)
+	}
+
+	return memes, nil
+}
+
+// ListAllMemes implements models.MemeRepo.
+func (m *MemeStore) ListAllMemes(ctx context.Context, offset, limit int) ([]models.Meme, error) {
 	var mps []psqlMeme
-	err := m.db.Select(&mps, `SELECT * FROM memes WHERE board_id IN (
-		SELECT board_id AS id FROM subscriptions WHERE user_id=$1
-		UNION
-		SELECT id FROM boards WHERE owner_id=$1
-	) ORDER BY id OFFSET $2 LIMIT $3`, userID, offset, limit)
+	err := m.db.SelectContext(ctx, &mps, "SELECT "+memeColumns+" FROM memes ORDER BY id OFFSET $1 LIMIT $2", offset, limit)
 	if err != nil {
 		return []models.Meme{}, fmt.Errorf("can't select: %w", err)
 	}
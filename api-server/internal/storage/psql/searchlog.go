@@ -0,0 +1,87 @@
+package psql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"memesearch/internal/config"
+	"memesearch/internal/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var _ models.SearchLogRepo = &SearchLogStore{}
+
+type SearchLogStore struct {
+	db *sqlx.DB
+}
+
+func NewSearchLogStore(cfg config.DatabaseConfig) (*SearchLogStore, error) {
+	db, err := connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &SearchLogStore{db: db}, nil
+}
+
+// AddSearch implements models.SearchLogRepo.
+func (s *SearchLogStore) AddSearch(ctx context.Context, e models.SearchLogEntry) error {
+	filter, err := json.Marshal(e.Filter)
+	if err != nil {
+		return fmt.Errorf("can't marshal filter: %w", err)
+	}
+	boards := make([]string, 0, len(e.Boards))
+	for _, b := range e.Boards {
+		boards = append(boards, string(b))
+	}
+	top := make([]string, 0, len(e.TopIDs))
+	for _, id := range e.TopIDs {
+		top = append(top, string(id))
+	}
+
+	_, err = s.db.ExecContext(ctx, `INSERT INTO search_log
+	(user_id, query, filter, boards, results, top_ids, top_score, ranker, latency_ms, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		e.UserID, e.Query, string(filter), pq.Array(boards), e.Results, pq.Array(top), e.TopScore, e.Ranker,
+		float64(e.Latency)/float64(time.Millisecond), e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("can't insert: %w", err)
+	}
+	return nil
+}
+
+// FrequentQueries implements models.SearchLogRepo.
+func (s *SearchLogStore) FrequentQueries(ctx context.Context, board models.BoardID, since time.Time, limit int) ([]models.QueryStat, error) {
+	return s.queryStats(ctx, "", board, since, limit)
+}
+
+// FailedQueries implements models.SearchLogRepo.
+func (s *SearchLogStore) FailedQueries(ctx context.Context, board models.BoardID, since time.Time, ranker string, maxScore float64, limit int) ([]models.QueryStat, error) {
+	return s.queryStats(ctx, "AND (results = 0 OR (ranker = $4 AND top_score < $5))", board, since, limit, ranker, maxScore)
+}
+
+func (s *SearchLogStore) queryStats(ctx context.Context, cond string, board models.BoardID, since time.Time, limit int, args ...any) ([]models.QueryStat, error) {
+	var rows []struct {
+		Query          string    `db:"query"`
+		Searches       int       `db:"searches"`
+		Users          int       `db:"users"`
+		AvgResults     float64   `db:"avg_results"`
+		LastSearchedAt time.Time `db:"last_searched_at"`
+	}
+	err := s.db.SelectContext(ctx, &rows, `SELECT query, COUNT(*) AS searches, COUNT(DISTINCT user_id) AS users,
+	AVG(results)::DOUBLE PRECISION AS avg_results, MAX(created_at) AS last_searched_at
+	FROM search_log WHERE boards @> ARRAY[$1]::text[] AND created_at > $2 `+cond+`
+	GROUP BY query ORDER BY searches DESC, query LIMIT $3`,
+		append([]any{board, since, limit}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("can't select: %w", err)
+	}
+
+	res := make([]models.QueryStat, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, models.QueryStat(r))
+	}
+	return res, nil
+}
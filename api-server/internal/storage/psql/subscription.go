@@ -56,3 +56,33 @@ func (s *SubStore) Unsubscribe(ctx context.Context, user models.UserID, board mo
 	}
 	return nil
 }
+
+// SetSubscriptionWeight implements models.SubsciptionRepo.
+func (s *SubStore) SetSubscriptionWeight(ctx context.Context, user models.UserID, board models.BoardID, weight float64) error {
+	res, err := s.db.ExecContext(ctx, "UPDATE subscriptions SET weight=$3 WHERE user_id=$1 AND board_id=$2", user, board, weight)
+	if err != nil {
+		return fmt.Errorf("can't update: %w", err)
+	}
+	return zeroRows(res, models.ErrSubNotFound)
+}
+
+// BoardPreferences implements models.SubsciptionRepo.
+func (s *SubStore) BoardPreferences(ctx context.Context, user models.UserID) (map[models.BoardID]models.BoardPreference, error) {
+	var rows []struct {
+		BoardID models.BoardID `db:"board_id"`
+		Own     bool           `db:"own"`
+		Weight  float64        `db:"weight"`
+	}
+	err := s.db.SelectContext(ctx, &rows, `
+	SELECT b.id AS board_id, b.owner_id = $1 AS own, COALESCE(s.weight, 1) AS weight
+	FROM boards b LEFT JOIN subscriptions s ON s.board_id = b.id AND s.user_id = $1
+	WHERE b.owner_id = $1 OR s.user_id IS NOT NULL`, user)
+	if err != nil {
+		return nil, fmt.Errorf("can't select: %w", err)
+	}
+	res := make(map[models.BoardID]models.BoardPreference, len(rows))
+	for _, r := range rows {
+		res[r.BoardID] = models.BoardPreference{Own: r.Own, Weight: r.Weight}
+	}
+	return res, nil
+}
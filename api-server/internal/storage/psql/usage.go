@@ -0,0 +1,62 @@
+package psql
+
+import (
+	"context"
+	"fmt"
+	"memesearch/internal/config"
+	"memesearch/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var _ models.UsageRepo = &UsageStore{}
+
+type UsageStore struct {
+	db *sqlx.DB
+}
+
+func NewUsageStore(cfg config.DatabaseConfig) (*UsageStore, error) {
+	db, err := connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &UsageStore{db: db}, nil
+}
+
+// AddUsage implements models.UsageRepo.
+func (u *UsageStore) AddUsage(ctx context.Context, e models.UsageEvent) error {
+	_, err := u.db.ExecContext(ctx, "INSERT INTO usage_events (meme_id, user_id, source, created_at) VALUES ($1, $2, $3, $4)",
+		e.MemeID, e.UserID, e.Source, e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("can't insert: %w", err)
+	}
+	return nil
+}
+
+// UsageCounts implements models.UsageRepo.
+func (u *UsageStore) UsageCounts(ctx context.Context, memes []models.MemeID, user models.UserID) (map[models.MemeID]models.UsageCount, error) {
+	ids := make([]string, 0, len(memes))
+	for _, m := range memes {
+		ids = append(ids, string(m))
+	}
+
+	var rows []struct {
+		MemeID models.MemeID `db:"meme_id"`
+		Global int           `db:"global"`
+		User   int           `db:"personal"`
+	}
+	err := u.db.SelectContext(ctx, &rows, `SELECT meme_id, COUNT(*) AS global,
+	COUNT(*) FILTER (WHERE user_id = $2) AS personal
+	FROM usage_events WHERE meme_id = ANY($1)
+	GROUP BY meme_id`, pq.Array(ids), user)
+	if err != nil {
+		return nil, fmt.Errorf("can't select: %w", err)
+	}
+
+	res := make(map[models.MemeID]models.UsageCount, len(rows))
+	for _, r := range rows {
+		res[r.MemeID] = models.UsageCount{Global: r.Global, User: r.User}
+	}
+	return res, nil
+}
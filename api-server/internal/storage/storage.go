@@ -13,8 +13,11 @@ type Storage struct {
 	models.BoardRepo
 	models.MemeRepo
 	models.MediaRepo
+	models.MediaHashRepo
 	models.UserRepo
 	models.SubsciptionRepo
+	models.UsageRepo
+	models.SearchLogRepo
 }
 
 func New(cfg config.Config) (s Storage, err error) {
@@ -34,9 +37,21 @@ func New(cfg config.Config) (s Storage, err error) {
 	if err != nil {
 		return Storage{}, fmt.Errorf("can't load media store: %w", err)
 	}
+	s.MediaHashRepo, err = psql.NewMediaHashStore(cfg.Database)
+	if err != nil {
+		return Storage{}, fmt.Errorf("can't load media hash store: %w", err)
+	}
 	s.SubsciptionRepo, err = psql.NewSubStore(cfg.Database)
 	if err != nil {
 		return Storage{}, fmt.Errorf("can't load media store: %w", err)
 	}
+	s.UsageRepo, err = psql.NewUsageStore(cfg.Database)
+	if err != nil {
+		return Storage{}, fmt.Errorf("can't load usage store: %w", err)
+	}
+	s.SearchLogRepo, err = psql.NewSearchLogStore(cfg.Database)
+	if err != nil {
+		return Storage{}, fmt.Errorf("can't load search log store: %w", err)
+	}
 	return s, nil
 }
@@ -0,0 +1,151 @@
+package vectorindex
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"sync"
+)
+
+// Embedder maps text to a unit vector, texts of similar meaning get vectors
+// with a large dot product. Embedders with state learned from the corpus
+// implement encoding.BinaryMarshaler and encoding.BinaryUnmarshaler to have
+// it persisted with the index.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// learner is implemented by embedders with statistics of the corpus. Vectors
+// computed before the statistics changed aren't comparable to new ones, so
+// the index recomputes every vector after Learn.
+type learner interface {
+	// Learn replaces the statistics with those of texts.
+	Learn(texts []string)
+}
+
+var _ Embedder = &NGramEmbedder{}
+
+// NGramEmbedder is a dependency-free embedder of hashed character trigram
+// TF-IDF vectors. Words sharing parts, like inflected forms or typos, get
+// similar vectors. Trigrams found in many texts weigh less.
+type NGramEmbedder struct {
+	mu sync.RWMutex
+	// df counts texts having a trigram in every dimension.
+	df   []uint32
+	docs uint32
+}
+
+func NewNGramEmbedder(dim int) *NGramEmbedder {
+	return &NGramEmbedder{df: make([]uint32, max(dim, 1))}
+}
+
+// Learn counts document frequencies of trigrams of texts.
+func (e *NGramEmbedder) Learn(texts []string) {
+	df := make([]uint32, len(e.df))
+	for _, text := range texts {
+		seen := map[int]struct{}{}
+		for g := range grams(text) {
+			d, _ := e.bucket(g)
+			if _, ok := seen[d]; !ok {
+				seen[d] = struct{}{}
+				df[d]++
+			}
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.df, e.docs = df, uint32(len(texts))
+}
+
+// Embed implements Embedder.
+func (e *NGramEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	vec := make([]float32, len(e.df))
+	for g, tf := range grams(text) {
+		d, sign := e.bucket(g)
+		idf := math.Log(float64(1+e.docs)/float64(1+e.df[d])) + 1
+		vec[d] += float32(sign * (1 + math.Log(float64(tf))) * idf)
+	}
+	normalize(vec)
+	return vec, nil
+}
+
+// bucket hashes a trigram to a dimension and a sign, signs make collisions
+// cancel out instead of adding up.
+func (e *NGramEmbedder) bucket(gram string) (int, float64) {
+	h := fnv.New32a()
+	h.Write([]byte(gram))
+	s := h.Sum32()
+	sign := 1.0
+	if s&1 == 1 {
+		sign = -1
+	}
+	return int((s >> 1) % uint32(len(e.df))), sign
+}
+
+type ngramState struct {
+	DF   []uint32
+	Docs uint32
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (e *NGramEmbedder) MarshalBinary() ([]byte, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(ngramState{DF: e.df, Docs: e.docs})
+	return buf.Bytes(), err
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It fails when the
+// state was saved with another dimension.
+func (e *NGramEmbedder) UnmarshalBinary(data []byte) error {
+	var st ngramState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&st); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(st.DF) != len(e.df) {
+		return fmt.Errorf("dimension %d doesn't match %d", len(st.DF), len(e.df))
+	}
+	e.df, e.docs = st.DF, st.Docs
+	return nil
+}
+
+// grams counts character trigrams of text words padded with word bounds.
+func grams(text string) map[string]int {
+	res := map[string]int{}
+	for _, w := range strings.Fields(strings.ToLower(text)) {
+		rs := []rune("<" + w + ">")
+		for i := 0; i+3 <= len(rs); i++ {
+			res[string(rs[i:i+3])]++
+		}
+	}
+	return res
+}
+
+func normalize(vec []float32) {
+	var sum float64
+	for _, v := range vec {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vec {
+		vec[i] /= norm
+	}
+}
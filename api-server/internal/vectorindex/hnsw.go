@@ -0,0 +1,293 @@
+package vectorindex
+
+import (
+	"container/heap"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// graph is a hierarchical navigable small world graph over unit vectors.
+// Nodes are numbered by insertion, removed nodes leave nil holes till the
+// graph is compacted. It isn't safe for concurrent use.
+type graph struct {
+	// m is the number of links per node on upper layers, layer 0 has 2*m.
+	m              int
+	efConstruction int
+	ml             float64
+	rng            *rand.Rand
+
+	nodes    []*node
+	entry    uint32
+	maxLevel int
+	size     int
+}
+
+type node struct {
+	vec []float32
+	// links holds neighbours on every layer up to the node level.
+	links [][]uint32
+}
+
+func newGraph() *graph {
+	const m = 16
+	return &graph{
+		m:              m,
+		efConstruction: 100,
+		ml:             1 / math.Log(m),
+		rng:            rand.New(rand.NewPCG(1, 2)),
+		maxLevel:       -1,
+	}
+}
+
+type candidate struct {
+	id   uint32
+	dist float32
+}
+
+// distance is the cosine distance of unit vectors.
+func distance(a, b []float32) float32 {
+	return 1 - dot(a, b)
+}
+
+func dot(a, b []float32) float32 {
+	var s float32
+	for i := range min(len(a), len(b)) {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+func (g *graph) maxLinks(level int) int {
+	if level == 0 {
+		return 2 * g.m
+	}
+	return g.m
+}
+
+// insert adds vec and returns its node ID.
+func (g *graph) insert(vec []float32) uint32 {
+	level := int(-math.Log(1-g.rng.Float64()) * g.ml)
+	id := uint32(len(g.nodes))
+	n := &node{vec: vec, links: make([][]uint32, level+1)}
+	g.nodes = append(g.nodes, n)
+	g.size++
+
+	if g.maxLevel < 0 {
+		g.entry, g.maxLevel = id, level
+		return id
+	}
+
+	ep := g.entry
+	for l := g.maxLevel; l > level; l-- {
+		ep = g.greedy(vec, ep, l)
+	}
+	for l := min(level, g.maxLevel); l >= 0; l-- {
+		cands := g.searchLayer(vec, ep, g.efConstruction, l)
+		n.links[l] = closest(cands, g.maxLinks(l))
+		for _, nb := range n.links[l] {
+			g.link(nb, id, l)
+		}
+		ep = cands[0].id
+	}
+	if level > g.maxLevel {
+		g.entry, g.maxLevel = id, level
+	}
+	return id
+}
+
+// link adds a link from a to b pruning the farthest links of a over the limit.
+func (g *graph) link(a, b uint32, level int) {
+	n := g.nodes[a]
+	n.links[level] = append(n.links[level], b)
+	if len(n.links[level]) > g.maxLinks(level) {
+		n.links[level] = g.prune(n.vec, n.links[level], g.maxLinks(level))
+	}
+}
+
+// prune keeps up to limit existing nodes of ids closest to vec.
+func (g *graph) prune(vec []float32, ids []uint32, limit int) []uint32 {
+	cands := make([]candidate, 0, len(ids))
+	for _, id := range ids {
+		if g.nodes[id] != nil {
+			cands = append(cands, candidate{id: id, dist: distance(vec, g.nodes[id].vec)})
+		}
+	}
+	slices.SortFunc(cands, func(a, b candidate) int { return cmpDist(a.dist, b.dist) })
+	return closest(cands, limit)
+}
+
+// remove deletes node id relinking its neighbours to each other.
+func (g *graph) remove(id uint32) {
+	n := g.nodes[id]
+	if n == nil {
+		return
+	}
+	g.nodes[id] = nil
+	g.size--
+
+	for l, links := range n.links {
+		for _, nb := range links {
+			m := g.nodes[nb]
+			if m == nil || len(m.links) <= l {
+				continue
+			}
+			ids := slices.DeleteFunc(m.links[l], func(x uint32) bool { return x == id })
+			for _, x := range links {
+				if x != nb && !slices.Contains(ids, x) {
+					ids = append(ids, x)
+				}
+			}
+			m.links[l] = g.prune(m.vec, ids, g.maxLinks(l))
+		}
+	}
+
+	if id != g.entry {
+		return
+	}
+	g.maxLevel = -1
+	for i, m := range g.nodes {
+		if m != nil && len(m.links)-1 > g.maxLevel {
+			g.entry, g.maxLevel = uint32(i), len(m.links)-1
+		}
+	}
+}
+
+// compact renumbers nodes dropping holes left by removed nodes and links to
+// them. It returns new IDs of the remaining nodes by their old IDs.
+func (g *graph) compact() map[uint32]uint32 {
+	renumbered := make(map[uint32]uint32, g.size)
+	nodes := make([]*node, 0, g.size)
+	for id, n := range g.nodes {
+		if n != nil {
+			renumbered[uint32(id)] = uint32(len(nodes))
+			nodes = append(nodes, n)
+		}
+	}
+	for _, n := range nodes {
+		for l, ls := range n.links {
+			kept := make([]uint32, 0, len(ls))
+			for _, x := range ls {
+				if y, ok := renumbered[x]; ok {
+					kept = append(kept, y)
+				}
+			}
+			n.links[l] = kept
+		}
+	}
+	g.nodes = nodes
+	g.entry = renumbered[g.entry]
+	return renumbered
+}
+
+// search returns up to ef nodes closest to vec ordered by distance.
+func (g *graph) search(vec []float32, ef int) []candidate {
+	if g.maxLevel < 0 {
+		return nil
+	}
+	ep := g.entry
+	for l := g.maxLevel; l > 0; l-- {
+		ep = g.greedy(vec, ep, l)
+	}
+	return g.searchLayer(vec, ep, ef, 0)
+}
+
+// greedy walks layer from ep to the node closest to vec.
+func (g *graph) greedy(vec []float32, ep uint32, level int) uint32 {
+	best := distance(vec, g.nodes[ep].vec)
+	for changed := true; changed; {
+		changed = false
+		for _, nb := range g.nodes[ep].links[level] {
+			if g.nodes[nb] == nil {
+				continue
+			}
+			if d := distance(vec, g.nodes[nb].vec); d < best {
+				ep, best, changed = nb, d, true
+			}
+		}
+	}
+	return ep
+}
+
+// searchLayer is the beam search of HNSW with beam width ef.
+func (g *graph) searchLayer(vec []float32, ep uint32, ef int, level int) []candidate {
+	start := candidate{id: ep, dist: distance(vec, g.nodes[ep].vec)}
+	visited := map[uint32]struct{}{ep: {}}
+	cands := &nearHeap{start}
+	found := &farHeap{start}
+
+	for cands.Len() > 0 {
+		c := heap.Pop(cands).(candidate)
+		if c.dist > (*found)[0].dist && found.Len() >= ef {
+			break
+		}
+		for _, nb := range g.nodes[c.id].links[level] {
+			if _, ok := visited[nb]; ok {
+				continue
+			}
+			visited[nb] = struct{}{}
+			m := g.nodes[nb]
+			if m == nil {
+				continue
+			}
+			d := distance(vec, m.vec)
+			if found.Len() < ef || d < (*found)[0].dist {
+				heap.Push(cands, candidate{id: nb, dist: d})
+				heap.Push(found, candidate{id: nb, dist: d})
+				if found.Len() > ef {
+					heap.Pop(found)
+				}
+			}
+		}
+	}
+
+	res := []candidate(*found)
+	slices.SortFunc(res, func(a, b candidate) int { return cmpDist(a.dist, b.dist) })
+	return res
+}
+
+func closest(sorted []candidate, limit int) []uint32 {
+	res := make([]uint32, 0, min(limit, len(sorted)))
+	for _, c := range sorted[:min(limit, len(sorted))] {
+		res = append(res, c.id)
+	}
+	return res
+}
+
+func cmpDist(a, b float32) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// nearHeap pops the closest candidate first.
+type nearHeap []candidate
+
+func (h nearHeap) Len() int           { return len(h) }
+func (h nearHeap) Less(i, j int) bool { return h[i].dist < h[j].dist }
+func (h nearHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *nearHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *nearHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// farHeap pops the farthest candidate first.
+type farHeap []candidate
+
+func (h farHeap) Len() int           { return len(h) }
+func (h farHeap) Less(i, j int) bool { return h[i].dist > h[j].dist }
+func (h farHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *farHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *farHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,431 @@
+// Package vectorindex retrieves memes by meaning of their descriptions.
+// Descriptions are embedded into vectors kept in an in-process HNSW graph
+// for approximate nearest neighbour search, which is persisted to a file.
+package vectorindex
+
+import (
+	"context"
+	"encoding"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log/slog"
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDrift is the share of memes which may be added, changed or removed
+// since the embedder learned the corpus before Sync learns it again.
+const maxDrift = 0.1
+
+// Index is safe for concurrent use.
+type Index struct {
+	an       searchranker.Analyzer
+	embedder Embedder
+
+	mu      sync.RWMutex
+	graph   *graph
+	entries map[models.MemeID]*entry
+	byNode  map[uint32]models.MemeID
+	// learned is the number of memes the embedder learned the corpus from,
+	// drift is the number of memes added, changed or removed since then.
+	learned int
+	drift   int
+	// dirty is set when the index changed since it was saved.
+	dirty bool
+}
+
+type entry struct {
+	meme models.Meme
+	node uint32
+	// hash of the description the vector was computed from.
+	hash uint64
+}
+
+// Hit is a meme near to the searched vector.
+type Hit struct {
+	Meme       models.Meme
+	Similarity float64
+}
+
+func New(an searchranker.Analyzer, embedder Embedder) *Index {
+	return &Index{
+		an:       an,
+		embedder: embedder,
+		graph:    newGraph(),
+		entries:  map[models.MemeID]*entry{},
+		byNode:   map[uint32]models.MemeID{},
+	}
+}
+
+// Open loads the index saved at path. An empty index is returned when there
+// is no file yet or it was saved with an incompatible embedder.
+func Open(path string, an searchranker.Analyzer, embedder Embedder) (*Index, error) {
+	idx := New(an, embedder)
+	if path == "" {
+		return idx, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't open vector index: %w", err)
+	}
+	defer f.Close()
+
+	err = idx.load(f)
+	if err != nil {
+		slog.Warn("Vector index is rebuilt", "path", path, "err", err)
+		return New(an, embedder), nil
+	}
+	return idx, nil
+}
+
+// Sync brings the index in line with every meme of repo. Vectors are
+// computed only for new memes and memes whose description changed, unless
+// more than maxDrift of memes changed since the embedder learned the corpus.
+// Then it learns the corpus again and every vector is computed anew.
+func (idx *Index) Sync(ctx context.Context, repo models.MemeRepo) error {
+	const batchSize = 200
+	memes := []models.Meme{}
+	for offset := 0; ; offset += batchSize {
+		batch, err := repo.ListAllMemes(ctx, offset, batchSize)
+		if err != nil {
+			return fmt.Errorf("can't list memes with offset %d: %w", offset, err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+		memes = append(memes, batch...)
+	}
+
+	seen := make(map[models.MemeID]struct{}, len(memes))
+	changed := 0
+	for _, m := range memes {
+		seen[m.ID] = struct{}{}
+		if e, ok := idx.get(m.ID); !ok || e.hash != descriptionHash(m) {
+			changed++
+		}
+	}
+	idx.mu.RLock()
+	stale := []models.MemeID{}
+	for id := range idx.entries {
+		if _, ok := seen[id]; !ok {
+			stale = append(stale, id)
+		}
+	}
+	drift := idx.drift + changed + len(stale)
+	relearn := float64(drift) > maxDrift*float64(idx.learned)
+	idx.mu.RUnlock()
+
+	l, learns := idx.embedder.(learner)
+	if !learns || !relearn {
+		for _, id := range stale {
+			idx.Remove(id)
+		}
+		for _, m := range memes {
+			if err := idx.Add(ctx, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// learn the corpus first, so that vectors don't come from statistics
+	// of a few memes
+	texts := make([]string, 0, len(memes))
+	for _, m := range memes {
+		texts = append(texts, idx.text(m))
+	}
+	l.Learn(texts)
+	idx.mu.Lock()
+	idx.graph = newGraph()
+	idx.entries = map[models.MemeID]*entry{}
+	idx.byNode = map[uint32]models.MemeID{}
+	idx.mu.Unlock()
+	for _, m := range memes {
+		if err := idx.Add(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.learned, idx.drift = len(memes), 0
+	return nil
+}
+
+// Add indexes meme replacing the previous version with the same ID. The
+// vector is recomputed only when the description changed with statistics
+// the embedder learned in the last Sync.
+func (idx *Index) Add(ctx context.Context, meme models.Meme) error {
+	hash := descriptionHash(meme)
+	prev, exists := idx.get(meme.ID)
+	if exists && prev.hash == hash {
+		idx.mu.Lock()
+		defer idx.mu.Unlock()
+		prev.meme = meme
+		idx.dirty = true
+		return nil
+	}
+
+	vec, err := idx.embedder.Embed(ctx, idx.text(meme))
+	if err != nil {
+		return fmt.Errorf("can't embed meme %s: %w", meme.ID, err)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(meme.ID)
+	node := idx.graph.insert(vec)
+	idx.entries[meme.ID] = &entry{meme: meme, node: node, hash: hash}
+	idx.byNode[node] = meme.ID
+	idx.drift++
+	idx.dirty = true
+	return nil
+}
+
+// Remove removes meme from the index.
+func (idx *Index) Remove(id models.MemeID) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if _, ok := idx.entries[id]; ok {
+		idx.remove(id)
+		idx.drift++
+	}
+}
+
+// remove removes meme from the graph, which is compacted once removed memes
+// left more holes in it than there are memes.
+func (idx *Index) remove(id models.MemeID) {
+	e, ok := idx.entries[id]
+	if !ok {
+		return
+	}
+	idx.graph.remove(e.node)
+	delete(idx.entries, id)
+	delete(idx.byNode, e.node)
+	idx.dirty = true
+
+	if len(idx.graph.nodes)-idx.graph.size > idx.graph.size {
+		idx.compact()
+	}
+}
+
+// compact renumbers graph nodes skipping holes left by removed memes.
+func (idx *Index) compact() {
+	renumbered := idx.graph.compact()
+	byNode := make(map[uint32]models.MemeID, len(idx.byNode))
+	for node, id := range idx.byNode {
+		n := renumbered[node]
+		byNode[n] = id
+		idx.entries[id].node = n
+	}
+	idx.byNode = byNode
+}
+
+func (idx *Index) get(id models.MemeID) (*entry, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	e, ok := idx.entries[id]
+	return e, ok
+}
+
+// EmbedQuery returns the vector of words of every query clause.
+func (idx *Index) EmbedQuery(ctx context.Context, q searchranker.Query) ([]float32, error) {
+	return idx.embedder.Embed(ctx, strings.Join(q.Words(), " "))
+}
+
+// Vector returns the vector of an indexed meme.
+func (idx *Index) Vector(id models.MemeID) ([]float32, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	e, ok := idx.entries[id]
+	if !ok {
+		return nil, false
+	}
+	return idx.graph.nodes[e.node].vec, true
+}
+
+// Nearest returns up to k memes of boards nearest to vec ordered by similarity.
+func (idx *Index) Nearest(vec []float32, boards []models.BoardID, k int) []Hit {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	// memes of other boards are skipped, so the beam widens till enough
+	// memes of boards are found
+	res := []Hit{}
+	for ef := max(2*k, 64); ; ef *= 2 {
+		res = res[:0]
+		for _, c := range idx.graph.search(vec, ef) {
+			e := idx.entries[idx.byNode[c.id]]
+			if len(boards) > 0 && !slices.Contains(boards, e.meme.BoardID) {
+				continue
+			}
+			res = append(res, Hit{Meme: e.meme, Similarity: float64(1 - c.dist)})
+			if len(res) == k {
+				return res
+			}
+		}
+		if ef >= idx.graph.size {
+			return res
+		}
+	}
+}
+
+// text is the analyzed description the vector of meme is computed from.
+func (idx *Index) text(meme models.Meme) string {
+	return strings.Join(searchranker.Tokens(meme, idx.an), " ")
+}
+
+func descriptionHash(meme models.Meme) uint64 {
+	fields := make([]string, 0, len(meme.Description))
+	for f, d := range meme.Description {
+		fields = append(fields, f+"\x00"+d)
+	}
+	slices.Sort(fields)
+
+	h := fnv.New64a()
+	h.Write([]byte(string(meme.BoardID) + "\x00" + strings.Join(fields, "\x00")))
+	return h.Sum64()
+}
+
+type savedIndex struct {
+	Embedder []byte
+	Entries  []savedEntry
+	Entry    uint32
+	MaxLevel int
+	Learned  int
+	Drift    int
+}
+
+type savedEntry struct {
+	Meme   models.Meme
+	Hash   uint64
+	Vector []float32
+	Links  [][]uint32
+}
+
+// Save writes the index to path replacing the previous file atomically.
+func (idx *Index) Save(path string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	saved := savedIndex{MaxLevel: idx.graph.maxLevel, Learned: idx.learned, Drift: idx.drift}
+	if m, ok := idx.embedder.(encoding.BinaryMarshaler); ok {
+		state, err := m.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("can't save embedder: %w", err)
+		}
+		saved.Embedder = state
+	}
+
+	// saved entries are numbered by nodes
+	idx.compact()
+	for node, n := range idx.graph.nodes {
+		e := idx.entries[idx.byNode[uint32(node)]]
+		saved.Entries = append(saved.Entries, savedEntry{Meme: e.meme, Hash: e.hash, Vector: n.vec, Links: n.links})
+	}
+	saved.Entry = idx.graph.entry
+
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return fmt.Errorf("can't create directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("can't create file: %w", err)
+	}
+	err = gob.NewEncoder(f).Encode(saved)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("can't encode index: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("can't close file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("can't replace index: %w", err)
+	}
+	idx.dirty = false
+	return nil
+}
+
+func (idx *Index) load(f *os.File) error {
+	var saved savedIndex
+	err := gob.NewDecoder(f).Decode(&saved)
+	if err != nil {
+		return fmt.Errorf("can't decode index: %w", err)
+	}
+
+	// the embedder is loaded last, so that a rejected file leaves it as it
+	// was for the rebuild
+	if n := len(saved.Entries); n > 0 && (int(saved.Entry) >= n || len(saved.Entries[saved.Entry].Links) != saved.MaxLevel+1) {
+		return fmt.Errorf("entry point %d doesn't match %d levels", saved.Entry, saved.MaxLevel+1)
+	}
+	for _, se := range saved.Entries {
+		for _, ls := range se.Links {
+			if slices.ContainsFunc(ls, func(x uint32) bool { return int(x) >= len(saved.Entries) }) {
+				return fmt.Errorf("link of meme %s out of range", se.Meme.ID)
+			}
+		}
+	}
+	if u, ok := idx.embedder.(encoding.BinaryUnmarshaler); ok {
+		if err := u.UnmarshalBinary(saved.Embedder); err != nil {
+			return fmt.Errorf("can't load embedder: %w", err)
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	g := idx.graph
+	for i, se := range saved.Entries {
+		g.nodes = append(g.nodes, &node{vec: se.Vector, links: se.Links})
+		idx.entries[se.Meme.ID] = &entry{meme: se.Meme, node: uint32(i), hash: se.Hash}
+		idx.byNode[uint32(i)] = se.Meme.ID
+	}
+	g.size = len(g.nodes)
+	if g.size > 0 {
+		g.entry, g.maxLevel = saved.Entry, saved.MaxLevel
+	}
+	idx.learned, idx.drift = saved.Learned, saved.Drift
+	return nil
+}
+
+// Persist saves the index to path every interval when it changed until ctx is done.
+func (idx *Index) Persist(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		idx.mu.RLock()
+		dirty := idx.dirty
+		idx.mu.RUnlock()
+		if !dirty {
+			continue
+		}
+		if err := idx.Save(path); err != nil {
+			slog.Error("Can't save vector index", "path", path, "err", err)
+		}
+	}
+}
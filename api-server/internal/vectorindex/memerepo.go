@@ -0,0 +1,67 @@
+package vectorindex
+
+import (
+	"context"
+	"fmt"
+	"memesearch/internal/models"
+)
+
+var _ models.MemeRepo = &MemeRepo{}
+
+// MemeRepo wraps models.MemeRepo and keeps Index in sync with every change
+// made through it.
+type MemeRepo struct {
+	models.MemeRepo
+	index *Index
+}
+
+func NewMemeRepo(repo models.MemeRepo, index *Index) *MemeRepo {
+	return &MemeRepo{
+		MemeRepo: repo,
+		index:    index,
+	}
+}
+
+// InsertMeme implements models.MemeRepo.
+func (r *MemeRepo) InsertMeme(ctx context.Context, meme models.Meme) (models.MemeID, error) {
+	id, err := r.MemeRepo.InsertMeme(ctx, meme)
+	if err != nil {
+		return "", err
+	}
+	if err := r.reindex(ctx, id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// UpdateMeme implements models.MemeRepo.
+func (r *MemeRepo) UpdateMeme(ctx context.Context, meme models.Meme) error {
+	err := r.MemeRepo.UpdateMeme(ctx, meme)
+	if err != nil {
+		return err
+	}
+	return r.reindex(ctx, meme.ID)
+}
+
+// DeleteMeme implements models.MemeRepo.
+func (r *MemeRepo) DeleteMeme(ctx context.Context, id models.MemeID) error {
+	err := r.MemeRepo.DeleteMeme(ctx, id)
+	if err != nil {
+		return err
+	}
+	r.index.Remove(id)
+	return nil
+}
+
+// reindex reloads meme from the wrapped repo, its vector is recomputed when
+// the description changed.
+func (r *MemeRepo) reindex(ctx context.Context, id models.MemeID) error {
+	meme, err := r.MemeRepo.GetMemeByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("can't get meme for vector index: %w", err)
+	}
+	if err := r.index.Add(ctx, meme); err != nil {
+		return fmt.Errorf("can't add meme to vector index: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package vectorindex
+
+import (
+	"context"
+	"fmt"
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+	"sort"
+)
+
+var (
+	_ searchranker.Ranker    = &Ranker{}
+	_ searchranker.Retriever = &Ranker{}
+)
+
+// Ranker scores memes by similarity of description and query vectors.
+// Memes nearest to the query are retrieved even when they share no words
+// with it.
+type Ranker struct {
+	Index *Index
+	// Neighbours is the number of nearest memes retrieved for a query.
+	Neighbours int
+	// Fuzzy scores memes by spelling. When set its score is blended with
+	// the similarity weighted by Blend.
+	Fuzzy searchranker.Ranker
+	Blend float64
+	// MinScore drops memes scoring lower.
+	MinScore float64
+}
+
+// Retrieve implements searchranker.Retriever.
+func (r *Ranker) Retrieve(ctx context.Context, boards []models.BoardID, req searchranker.Request) ([]models.Meme, error) {
+	vec, err := r.Index.EmbedQuery(ctx, req.Query)
+	if err != nil {
+		return nil, fmt.Errorf("can't embed query: %w", err)
+	}
+	hits := r.Index.Nearest(vec, boards, r.Neighbours)
+	res := make([]models.Meme, 0, len(hits))
+	for _, h := range hits {
+		res = append(res, h.Meme)
+	}
+	return res, nil
+}
+
+// Rank implements searchranker.Ranker.
+func (r *Ranker) Rank(ctx context.Context, memes []models.Meme, req searchranker.Request) ([]searchranker.ScroredMeme, error) {
+	vec, err := r.Index.EmbedQuery(ctx, req.Query)
+	if err != nil {
+		return nil, fmt.Errorf("can't embed query: %w", err)
+	}
+
+	fuzzy := map[models.MemeID]searchranker.ScroredMeme{}
+	if r.Fuzzy != nil && r.Blend > 0 {
+		res, err := r.Fuzzy.Rank(ctx, memes, req)
+		if err != nil {
+			return nil, fmt.Errorf("can't rank fuzzy: %w", err)
+		}
+		for _, m := range res {
+			fuzzy[m.Meme.ID] = m
+		}
+	}
+
+	res := make([]searchranker.ScroredMeme, 0, len(memes))
+	for _, m := range memes {
+		mvec, ok := r.Index.Vector(m.ID)
+		if !ok {
+			continue
+		}
+		sm := searchranker.ScroredMeme{Meme: m, Score: float64(dot(vec, mvec))}
+		if r.Fuzzy != nil && r.Blend > 0 {
+			f := fuzzy[m.ID]
+			sm.Score = (1-r.Blend)*sm.Score + r.Blend*f.Score
+			sm.Corrections, sm.Explain = f.Corrections, f.Explain
+		}
+		if sm.Score < r.MinScore {
+			continue
+		}
+		res = append(res, sm)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Score > res[j].Score
+	})
+	return res, nil
+}
@@ -0,0 +1,227 @@
+package vectorindex
+
+import (
+	"context"
+	"encoding/gob"
+	"math/rand/v2"
+	"memesearch/internal/models"
+	"memesearch/internal/searchranker"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomVector(rng *rand.Rand, dim int) []float32 {
+	vec := make([]float32, dim)
+	for i := range vec {
+		vec[i] = float32(rng.NormFloat64())
+	}
+	normalize(vec)
+	return vec
+}
+
+func TestGraphRecall(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	g := newGraph()
+	vecs := [][]float32{}
+	for range 1000 {
+		v := randomVector(rng, 16)
+		vecs = append(vecs, v)
+		g.insert(v)
+	}
+	for id := uint32(0); id < 1000; id += 3 {
+		g.remove(id)
+	}
+	// remaining nodes keep their order, so that ids map to vecs below
+	renumbered := g.compact()
+	require.Len(t, g.nodes, g.size)
+	ids := make(map[uint32]uint32, len(renumbered))
+	for old, id := range renumbered {
+		ids[id] = old
+	}
+
+	const k = 10
+	found := 0
+	for range 50 {
+		q := randomVector(rng, 16)
+		exact := []candidate{}
+		for id, v := range vecs {
+			if id%3 != 0 {
+				exact = append(exact, candidate{id: uint32(id), dist: distance(q, v)})
+			}
+		}
+		slices.SortFunc(exact, func(a, b candidate) int { return cmpDist(a.dist, b.dist) })
+
+		got := closest(g.search(q, 64), k)
+		for i, id := range got {
+			got[i] = ids[id]
+		}
+		for _, c := range exact[:k] {
+			if slices.Contains(got, c.id) {
+				found++
+			}
+		}
+		assert.False(t, slices.ContainsFunc(got, func(id uint32) bool { return id%3 == 0 }))
+	}
+	assert.Greater(t, float64(found)/(50*k), 0.9)
+}
+
+func TestEmbedder(t *testing.T) {
+	e := NewNGramEmbedder(256)
+	e.Learn([]string{"грустный кот", "весёлая собака", "кот спит"})
+	embed := func(text string) []float32 {
+		v, err := e.Embed(context.Background(), text)
+		require.NoError(t, err)
+		return v
+	}
+
+	cat := embed("котик")
+	assert.Greater(t, dot(cat, embed("кот спит")), dot(cat, embed("весёлая собака")))
+	assert.InDelta(t, 1, dot(cat, cat), 1e-5)
+}
+
+func TestIndex(t *testing.T) {
+	ctx := context.Background()
+	an := searchranker.StemmingAnalyzer{}
+	idx := New(an, NewNGramEmbedder(256))
+	for i, d := range []string{"грустный кот", "весёлая собака", "котик спит", "смешной попугай"} {
+		id := models.MemeID(strconv.Itoa(i + 1))
+		board := models.BoardID("a")
+		if i == 2 {
+			board = "b"
+		}
+		require.NoError(t, idx.Add(ctx, models.Meme{ID: id, BoardID: board, Description: map[string]string{"general": d}}))
+	}
+
+	q, err := searchranker.Parse("кошка", "general", an, nil)
+	require.NoError(t, err)
+	vec, err := idx.EmbedQuery(ctx, q)
+	require.NoError(t, err)
+	hits := idx.Nearest(vec, []models.BoardID{"a"}, 2)
+	require.Len(t, hits, 2)
+	assert.Equal(t, models.MemeID("1"), hits[0].Meme.ID)
+
+	idx.Remove("1")
+	path := filepath.Join(t.TempDir(), "vectors.gob")
+	require.NoError(t, idx.Save(path))
+
+	loaded, err := Open(path, an, NewNGramEmbedder(256))
+	require.NoError(t, err)
+	hits = loaded.Nearest(vec, nil, 3)
+	require.Len(t, hits, 3)
+	assert.Equal(t, models.MemeID("3"), hits[0].Meme.ID)
+
+	// another dimension can't reuse saved vectors
+	rebuilt, err := Open(path, an, NewNGramEmbedder(128))
+	require.NoError(t, err)
+	assert.Empty(t, rebuilt.Nearest(vec, nil, 3))
+}
+
+func TestOpenCorrupted(t *testing.T) {
+	ctx := context.Background()
+	an := searchranker.StemmingAnalyzer{}
+	e := NewNGramEmbedder(256)
+	idx := New(an, e)
+	e.Learn([]string{"грустный кот", "весёлая собака"})
+	for i, d := range []string{"грустный кот", "весёлая собака"} {
+		require.NoError(t, idx.Add(ctx, models.Meme{ID: models.MemeID(strconv.Itoa(i + 1)), BoardID: "a", Description: map[string]string{"general": d}}))
+	}
+	path := filepath.Join(t.TempDir(), "vectors.gob")
+	require.NoError(t, idx.Save(path))
+
+	// break a link of the saved graph
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	var saved savedIndex
+	require.NoError(t, gob.NewDecoder(f).Decode(&saved))
+	require.NoError(t, f.Close())
+	saved.Entries[0].Links[0] = append(saved.Entries[0].Links[0], 99)
+	f, err = os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, gob.NewEncoder(f).Encode(saved))
+	require.NoError(t, f.Close())
+
+	fresh := NewNGramEmbedder(256)
+	rebuilt, err := Open(path, an, fresh)
+	require.NoError(t, err)
+	assert.Empty(t, rebuilt.Nearest(make([]float32, 256), nil, 3))
+	// the embedder must not keep statistics of the rejected file
+	assert.Zero(t, fresh.docs)
+	assert.Equal(t, make([]uint32, 256), fresh.df)
+}
+
+// memeRepo lists memes, other methods panic.
+type memeRepo struct {
+	models.MemeRepo
+	memes []models.Meme
+}
+
+func (r *memeRepo) ListAllMemes(ctx context.Context, offset, limit int) ([]models.Meme, error) {
+	return r.memes[min(offset, len(r.memes)):min(offset+limit, len(r.memes))], nil
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	an := searchranker.StemmingAnalyzer{}
+	e := NewNGramEmbedder(256)
+	idx := New(an, e)
+	repo := &memeRepo{}
+	for i, d := range []string{"грустный кот", "весёлая собака", "котик спит", "смешной попугай", "злая собака", "кот и пёс", "пёс спит", "мем", "ещё мем", "кот"} {
+		repo.memes = append(repo.memes, models.Meme{ID: models.MemeID(strconv.Itoa(i + 1)), BoardID: "a", Description: map[string]string{"general": d}})
+	}
+	require.NoError(t, idx.Sync(ctx, repo))
+	assert.Equal(t, uint32(10), e.docs)
+	vec, ok := idx.Vector("1")
+	require.True(t, ok)
+
+	// a single change keeps statistics and vectors of other memes
+	repo.memes[1].Description = map[string]string{"general": "добрая собака"}
+	require.NoError(t, idx.Sync(ctx, repo))
+	assert.Equal(t, uint32(10), e.docs)
+	same, _ := idx.Vector("1")
+	assert.Equal(t, vec, same)
+
+	// more changes make the corpus learned again without counting edited
+	// memes twice, every vector is computed with the new statistics
+	repo.memes[2].Description = map[string]string{"general": "котик ест"}
+	repo.memes = repo.memes[:9]
+	require.NoError(t, idx.Sync(ctx, repo))
+	assert.Equal(t, uint32(9), e.docs)
+	relearned, _ := idx.Vector("1")
+	want, err := e.Embed(ctx, idx.text(repo.memes[0]))
+	require.NoError(t, err)
+	assert.Equal(t, want, relearned)
+	assert.NotEqual(t, vec, relearned)
+	_, ok = idx.Vector("10")
+	assert.False(t, ok)
+}
+
+func TestIndexCompacts(t *testing.T) {
+	ctx := context.Background()
+	idx := New(searchranker.StemmingAnalyzer{}, NewNGramEmbedder(64))
+	for i := range 20 {
+		id := models.MemeID(strconv.Itoa(i))
+		require.NoError(t, idx.Add(ctx, models.Meme{ID: id, BoardID: "a", Description: map[string]string{"general": "мем номер " + string(id)}}))
+	}
+	// every edit leaves a hole
+	for range 3 {
+		for i := range 20 {
+			id := models.MemeID(strconv.Itoa(i))
+			require.NoError(t, idx.Add(ctx, models.Meme{ID: id, BoardID: "a", Description: map[string]string{"general": "мем " + string(id)}}))
+			idx.Remove(id)
+			require.NoError(t, idx.Add(ctx, models.Meme{ID: id, BoardID: "a", Description: map[string]string{"general": "мем номер " + string(id)}}))
+		}
+	}
+	assert.LessOrEqual(t, len(idx.graph.nodes), 2*idx.graph.size)
+
+	vec, ok := idx.Vector("7")
+	require.True(t, ok)
+	hits := idx.Nearest(vec, nil, 1)
+	require.Len(t, hits, 1)
+	assert.Equal(t, models.MemeID("7"), hits[0].Meme.ID)
+}
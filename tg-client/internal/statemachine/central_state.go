@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"strings"
 )
 
@@ -65,6 +66,28 @@ func (s *CentralState) Process(r RequestContext) (State, error) {
 		case "/listboards":
 			err := doListBoards(r)
 			return s, err
+		case "/synonyms":
+			err := doListSynonyms(r)
+			return s, err
+		case "/addsynonyms":
+			if len(args) < 1 {
+				return s, ErrBadCommandUsage
+			}
+			err := doAddSynonyms(r, strings.Join(args, " "))
+			return s, err
+		case "/delsynonyms":
+			if len(args) < 1 {
+				return s, ErrBadCommandUsage
+			}
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				return s, ErrBadCommandUsage
+			}
+			err = doDeleteSynonyms(r, n)
+			return s, err
+		case "/queries":
+			err := doListQueries(r)
+			return s, err
 		case "/subscribe":
 			if len(args) < 1 {
 				return s, ErrBadCommandUsage
@@ -77,19 +100,56 @@ func (s *CentralState) Process(r RequestContext) (State, error) {
 			}
 			err := doUnsubscribe(r, models.BoardID(args[0]))
 			return s, err
+		case "/weight":
+			if len(args) < 2 {
+				return s, ErrBadCommandUsage
+			}
+			weight, err := strconv.ParseFloat(args[1], 64)
+			if err != nil {
+				return s, ErrBadCommandUsage
+			}
+			err = doSetWeight(r, models.BoardID(args[0]), weight)
+			return s, err
 		case "/search":
 			text := strings.Join(args[:], " ")
+			cursor := ""
+			mv := MediaViewState{page: 1, skip: true, getMedias: func(ctx context.Context, page, pageSize int) ([]models.ScoredMeme, error) {
+				if page > 1 && cursor == "" {
+					return nil, nil
+				}
+				res, err := r.ApiClient.SearchMemes(ctx, 0, pageSize, models.SearchQuery{General: text, Cursor: cursor})
+				cursor = res.NextCursor
+				return res.Memes, err
+			}}
+			return mv.Process(r)
+		case "/searchboard":
+			text := strings.Join(args[:], " ")
+			board := r.UserInfo.ActiveBoard
+			cursor := ""
 			mv := MediaViewState{page: 1, skip: true, getMedias: func(ctx context.Context, page, pageSize int) ([]models.ScoredMeme, error) {
-				return r.ApiClient.SearchMemes(ctx, (page-1)*pageSize, pageSize, text)
+				if page > 1 && cursor == "" {
+					return nil, nil
+				}
+				res, err := r.ApiClient.SearchBoardMemes(ctx, board, 0, pageSize, models.SearchQuery{General: text, Cursor: cursor})
+				cursor = res.NextCursor
+				return res.Memes, err
 			}}
 			return mv.Process(r)
+		case "/find":
+			r.SendMessage("Send me a picture and I'll find its meme.")
+			return &FindState{}, nil
 		default:
 			r.SendMessage("Unknown command, please use /help")
 			return s, nil
 		}
-	case isAddPhoto(r), isAddVideo(r):
-		err := doAddMedia(r)
+	case isFindPhoto(r):
+		err := doFind(r)
 		return &CentralState{}, err
+	case isAddPhoto(r), isAddVideo(r):
+		return doAddMedia(r)
+	case isCallback(r, ""):
+		r.Bot.AnswerCallback(r.Ctx, r.Event.CallbackQuery.ID, "This offer has expired")
+		return &CentralState{}, nil
 	default:
 		r.SendMessage("Please use /help to see what i can do.")
 		return &CentralState{}, nil
@@ -135,25 +195,35 @@ func isAddVideo(r RequestContext) bool {
 	return true
 }
 
-func doAddMedia(r RequestContext) error {
+// doAddMedia creates a meme of the sent media and offers description words
+// when the caption is short.
+func doAddMedia(r RequestContext) (State, error) {
 	ctx := r.Ctx
 	msg := r.Event.Message
 	filename, media, err := r.Bot.GetFile(ctx, msg)
 	if err != nil {
-		return fmt.Errorf("can't get files: %w", err)
+		return &CentralState{}, fmt.Errorf("can't get files: %w", err)
 
 	}
 
 	meme, err := r.ApiClient.PostMeme(ctx, r.UserInfo.ActiveBoard, filename, map[string]string{"general": msg.Caption})
 	if err != nil {
-		return fmt.Errorf("can't create meme: %w", err)
+		return &CentralState{}, fmt.Errorf("can't create meme: %w", err)
+	}
+	err = r.ApiClient.PutMediaByID(ctx, models.Media{ID: models.MediaID(meme.ID), Body: media}, filename, false)
+	var duplicate models.ErrDuplicateMedia
+	if errors.As(err, &duplicate) {
+		if _, err := r.ApiClient.DeleteMemeByID(ctx, meme.ID); err != nil {
+			return &CentralState{}, fmt.Errorf("can't delete duplicate meme: %w", err)
+		}
+		r.SendMessageReply(fmt.Sprintf("This media is already on the board as <code>%s</code>", duplicate.Meme), msg.MessageID)
+		return &CentralState{}, nil
 	}
-	err = r.ApiClient.PutMediaByID(ctx, models.Media{ID: models.MediaID(meme.ID), Body: media}, filename)
 	if err != nil {
-		return fmt.Errorf("can't set media: %w", err)
+		return &CentralState{}, fmt.Errorf("can't set media: %w", err)
 	}
 	slog.InfoContext(ctx, "Meme created",
 		"id", meme.ID)
 	r.SendMessageReply(fmt.Sprintf("<code>%s</code>", meme.ID), msg.MessageID)
-	return nil
+	return offerDescription(r, meme.ID, msg.Caption, msg.MessageID), nil
 }
@@ -0,0 +1,107 @@
+package statemachine
+
+import (
+	"api-client/pkg/models"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+var _ State = &DescribeState{}
+
+// DescribeState offers words for the description of a just added meme as
+// buttons, pressing one adds the word to the description. Other events are
+// processed as usual.
+type DescribeState struct {
+	meme  models.MemeID
+	words []string
+}
+
+const (
+	describeCallbackPrefix = "describe:"
+	// shortCaptionWords is the number of caption words below which
+	// description words are suggested.
+	shortCaptionWords    = 3
+	describeButtonsInRow = 3
+)
+
+// Process implements State.
+func (d *DescribeState) Process(r RequestContext) (State, error) {
+	if !isCallback(r, describeCallbackPrefix) {
+		return (&CentralState{}).Process(r)
+	}
+	q := r.Event.CallbackQuery
+	i, err := strconv.Atoi(strings.TrimPrefix(q.Data, describeCallbackPrefix))
+	if err != nil || i < 0 || i >= len(d.words) {
+		r.Bot.AnswerCallback(r.Ctx, q.ID, "Unknown word")
+		return d, nil
+	}
+
+	word := d.words[i]
+	err = addDescriptionWord(r, d.meme, word)
+	if err != nil {
+		return d, fmt.Errorf("can't add word: %w", err)
+	}
+	r.Bot.AnswerCallback(r.Ctx, q.ID, "Added: "+word)
+	return d, nil
+}
+
+// isCallback reports whether an inline button with data starting with prefix is pressed.
+func isCallback(r RequestContext, prefix string) bool {
+	return r.Event != nil && r.Event.CallbackQuery != nil && strings.HasPrefix(r.Event.CallbackQuery.Data, prefix)
+}
+
+// offerDescription replies to the message of a meme created with a short
+// caption with buttons of suggested description words.
+func offerDescription(r RequestContext, meme models.MemeID, caption string, replyTo int) State {
+	if len(strings.Fields(caption)) >= shortCaptionWords {
+		return &CentralState{}
+	}
+	sugs, err := r.ApiClient.SuggestDescription(r.Ctx, meme, 9)
+	if err != nil {
+		slog.ErrorContext(r.Ctx, "Can't suggest description", "err", err)
+		return &CentralState{}
+	}
+	if len(sugs) == 0 {
+		return &CentralState{}
+	}
+
+	d := &DescribeState{meme: meme}
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	for i, s := range sugs {
+		if i%describeButtonsInRow == 0 {
+			rows = append(rows, []tgbotapi.InlineKeyboardButton{})
+		}
+		d.words = append(d.words, s.Word)
+		rows[len(rows)-1] = append(rows[len(rows)-1], tgbotapi.NewInlineKeyboardButtonData(s.Word, describeCallbackPrefix+strconv.Itoa(i)))
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, err = r.Bot.SendMessage(r.Ctx, r.MustChat(), "Describe the meme better to find it later. Press words to add them:", nil, &replyTo, markup)
+	if err != nil {
+		slog.ErrorContext(r.Ctx, "Can't offer description", "err", err)
+		return &CentralState{}
+	}
+	return d
+}
+
+// addDescriptionWord appends word to the general description of meme.
+func addDescriptionWord(r RequestContext, id models.MemeID, word string) error {
+	ctx := r.Ctx
+	meme, err := r.ApiClient.GetMemeByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("can't get meme: %w", err)
+	}
+	dsc := map[string]string{}
+	for f, v := range meme.Descriptions {
+		dsc[f] = v
+	}
+	dsc["general"] = strings.TrimSpace(dsc["general"] + " " + word)
+	_, err = r.ApiClient.UpdateMemeByID(ctx, id, nil, nil, &dsc)
+	if err != nil {
+		return fmt.Errorf("can't update meme: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package statemachine
+
+import (
+	"fmt"
+	"strings"
+)
+
+var _ State = &FindState{}
+
+// FindState waits for a picture and replies with memes which look like it.
+type FindState struct {
+}
+
+// Process implements State.
+func (f *FindState) Process(r RequestContext) (State, error) {
+	switch {
+	case isAddPhoto(r):
+		err := doFind(r)
+		return &CentralState{}, err
+	case isCommand(r) && r.Event.Message.Text == "/exit":
+		r.SendMessage("Exited search by picture.")
+		return &CentralState{}, nil
+	default:
+		r.SendMessage("Send me a picture to find its meme or /exit.")
+		return f, nil
+	}
+}
+
+// isFindPhoto reports whether a picture is sent with /find caption.
+func isFindPhoto(r RequestContext) bool {
+	return isAddPhoto(r) && strings.HasPrefix(r.Event.Message.Caption, "/find")
+}
+
+func doFind(r RequestContext) error {
+	ctx := r.Ctx
+	msg := r.Event.Message
+	_, media, err := r.Bot.GetFile(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("can't get files: %w", err)
+	}
+
+	memes, err := r.ApiClient.SearchMemesByImage(ctx, 0, 10, media)
+	if err != nil {
+		return fmt.Errorf("can't search by image: %w", err)
+	}
+	if len(memes) == 0 {
+		r.SendMessageReply("No similar memes found", msg.MessageID)
+		return nil
+	}
+	sendMemes(r, memes)
+	return nil
+}
@@ -4,8 +4,11 @@ import (
 	"api-client/pkg/models"
 	"errors"
 	"fmt"
+	"html"
 	"log/slog"
+	"slices"
 	"strings"
+	"time"
 )
 
 func doRegister(r RequestContext, login, password string) error {
@@ -115,6 +118,92 @@ func doListBoards(r RequestContext) error {
 
 }
 
+func doListSynonyms(r RequestContext) error {
+	groups, err := r.ApiClient.GetBoardSynonyms(r.Ctx, r.UserInfo.ActiveBoard)
+	if err != nil {
+		return fmt.Errorf("can't get synonyms: %w", err)
+	}
+	if len(groups) == 0 {
+		r.SendMessage("The board has no synonyms")
+		return nil
+	}
+
+	msg := strings.Builder{}
+	for i, g := range groups {
+		msg.WriteString(fmt.Sprintf("%d. %s\n", i+1, html.EscapeString(strings.Join(g, ", "))))
+	}
+	r.SendMessage(msg.String())
+	return nil
+}
+
+func doListQueries(r RequestContext) error {
+	const limit = 10
+	ctx := r.Ctx
+	board := r.UserInfo.ActiveBoard
+	frequent, err := r.ApiClient.GetFrequentQueries(ctx, board, time.Time{}, limit)
+	if errors.Is(err, models.ErrForbidden) {
+		r.SendMessage("Only the owner of the board can see its queries")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("can't get frequent queries: %w", err)
+	}
+	failed, err := r.ApiClient.GetFailedQueries(ctx, board, time.Time{}, nil, limit)
+	if err != nil {
+		return fmt.Errorf("can't get failed queries: %w", err)
+	}
+
+	msg := strings.Builder{}
+	msg.WriteString("<b>Frequent queries</b>\n")
+	writeQueryStats(&msg, frequent)
+	msg.WriteString("\n<b>Queries finding nothing good</b>\n")
+	writeQueryStats(&msg, failed)
+	r.SendMessage(msg.String())
+	return nil
+}
+
+func writeQueryStats(msg *strings.Builder, stats []models.QueryStat) {
+	if len(stats) == 0 {
+		msg.WriteString("none\n")
+	}
+	for i, st := range stats {
+		msg.WriteString(fmt.Sprintf("%d. %s - %d searches by %d users\n", i+1, html.EscapeString(st.Query), st.Searches, st.Users))
+	}
+}
+
+func doAddSynonyms(r RequestContext, text string) error {
+	ctx := r.Ctx
+	groups, err := r.ApiClient.GetBoardSynonyms(ctx, r.UserInfo.ActiveBoard)
+	if err != nil {
+		return fmt.Errorf("can't get synonyms: %w", err)
+	}
+	groups = append(groups, strings.Split(text, ","))
+	err = r.ApiClient.SetBoardSynonyms(ctx, r.UserInfo.ActiveBoard, groups)
+	if err != nil {
+		return fmt.Errorf("can't set synonyms: %w", err)
+	}
+	r.SendMessage("Synonyms added")
+	return nil
+}
+
+func doDeleteSynonyms(r RequestContext, n int) error {
+	ctx := r.Ctx
+	groups, err := r.ApiClient.GetBoardSynonyms(ctx, r.UserInfo.ActiveBoard)
+	if err != nil {
+		return fmt.Errorf("can't get synonyms: %w", err)
+	}
+	if n < 1 || n > len(groups) {
+		return ErrBadCommandUsage
+	}
+	groups = slices.Delete(groups, n-1, n)
+	err = r.ApiClient.SetBoardSynonyms(ctx, r.UserInfo.ActiveBoard, groups)
+	if err != nil {
+		return fmt.Errorf("can't set synonyms: %w", err)
+	}
+	r.SendMessage("Synonyms deleted")
+	return nil
+}
+
 func doSubscribe(r RequestContext, id models.BoardID) error {
 	ctx := r.Ctx
 	err := r.ApiClient.SubscribeByBoardID(ctx, id)
@@ -128,6 +217,25 @@ func doSubscribe(r RequestContext, id models.BoardID) error {
 	}
 	return nil
 }
+
+func doSetWeight(r RequestContext, id models.BoardID, weight float64) error {
+	ctx := r.Ctx
+	err := r.ApiClient.SetSubscriptionWeight(ctx, id, weight)
+	if errors.Is(err, models.ErrSubNotFound) {
+		r.SendMessage("You aren't subscribed to this board")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("can't set weight: %w", err)
+	}
+
+	_, err = r.SendMessage("Success")
+	if err != nil {
+		return fmt.Errorf("can't send message: %w", err)
+	}
+	return nil
+}
+
 func doUnsubscribe(r RequestContext, id models.BoardID) error {
 	ctx := r.Ctx
 	err := r.ApiClient.UnsubscribeByBoardID(ctx, id)
@@ -182,7 +290,15 @@ func unwrap(e error) error {
 
 func help() string {
 	return `MemeSearch - бот для поиска мемов по описанию
-1) Поиск: осуществляется командой /search query либо inline запросом @MemeManiac query. Для поиска по видео используйте @MemeManiac !query
+1) Поиск: осуществляется командой /search query либо inline запросом @MemeManiac query. Для поиска по видео используйте @MemeManiac type:video query или @MemeManiac !query
+	"точная фраза" - слова подряд без опечаток
+	-слово - исключить мемы со словом
+	кот OR пёс - любое из слов
+	tags:кот - искать только в поле tags
+	board:id - искать только на доске id
+	/searchboard query - искать только на текущей активной доске, даже без подписки на нее
+	под результатами /search и /searchboard нажмите номер мема, чтобы получить его отдельным сообщением
+	/find - найти мем по картинке: пришлите картинку после команды или с подписью /find
 2) Бот учитывает аккаунт(сервиса MemeSearch, не телегерама) с которого приходят запросы и использует мемы доступные этому аккаунту.
 3) Команды для работы с аккаунтом:
 	/register login password - регистраиция
@@ -196,6 +312,11 @@ func help() string {
 	/listboards - Перечислить доступные доски
 	/subscibe id - Подписаться на доску id чтобы иметь доступ к ее мемам
 	/unsubscribe id - Отписаться от доски id
-5) Для того чтобы создать мем, пришлите фото/виде с описанием. Данный мем будет создан на текущую активную доску
+	/weight id 1.5 - Задать вес подписки на доску id от 0 до 10. Мемы доски с большим весом выше в поиске
+	/synonyms - Синонимы текущей доски. Поиск по мемам доски находит слово по любому синониму из группы
+	/addsynonyms кот, котик, cat - Добавить группу синонимов на текущую доску
+	/delsynonyms n - Удалить группу синонимов номер n
+	/queries - Частые запросы подписчиков текущей доски и запросы, по которым ничего хорошего не нашлось. Подскажут, каких описаний не хватает мемам. Доступно владельцу доски
+5) Для того чтобы создать мем, пришлите фото/виде с описанием. Данный мем будет создан на текущую активную доску. Если такая же картинка уже есть на доске, бот пришлет ID существующего мема. Если описание короткое, бот предложит слова из описаний похожих картинок и частые слова доски: нажмите на слово, чтобы добавить его в описание
 `
 }
@@ -2,10 +2,9 @@ package statemachine
 
 import (
 	"api-client/pkg/models"
-	"errors"
 	"fmt"
 	"log/slog"
-	"strconv"
+	"strings"
 	"tg-client/internal/telegram"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -14,50 +13,96 @@ import (
 func processInline(q *tgbotapi.InlineQuery, r RequestContext) {
 	ctx := r.Ctx
 
-	page := 1
-	if q.Offset != "" {
-		var err error
-		page, err = strconv.Atoi(q.Offset)
-		if err != nil {
-			// очень жаль
-		}
-	}
-	req := q.Query
-	filter := telegram.CMPhoto
-	if len(req) > 0 && req[0] == '!' {
-		filter = telegram.CMVideo
-		req = req[1:]
-
-	}
+	req, filter := inlineFilter(q.Query)
 
-	memes, err := r.ApiClient.SearchMemes(ctx, (page-1)*50, 50, req)
+	// the offset of the next results is the search cursor, so that pages
+	// don't shift when memes are added while scrolling
+	page, err := r.ApiClient.SearchMemes(ctx, 0, 50, models.SearchQuery{General: req, Filter: filter, Cursor: q.Offset})
 	if err != nil {
 		slog.ErrorContext(ctx, "Can't search", "err", err)
 		return
 	}
-	newOffset := ""
-	if len(memes) != 0 {
-		newOffset = fmt.Sprintf("%d", page+1)
-	}
 	inlineResponse := []any{}
-	for _, meme := range memes {
-		entry, err := prepareMeme(meme.Meme, r, filter)
-
+	if len(page.Memes) == 0 && q.Offset == "" && req != "" {
+		if entry, ok := prepareSuggestion(r, req); ok {
+			inlineResponse = append(inlineResponse, entry)
+		}
+	}
+	for _, meme := range page.Memes {
+		entry, err := prepareMeme(meme.Meme, r)
 		if err != nil {
-			if err != ErrSkipped {
-				slog.ErrorContext(ctx, "Can't prepare meme", "err", err)
-			}
+			slog.ErrorContext(ctx, "Can't prepare meme", "err", err)
 			continue
 		}
 		inlineResponse = append(inlineResponse, entry)
 	}
 
-	r.Bot.AnswerInlineQuery(ctx, q.ID, inlineResponse, newOffset)
+	r.Bot.AnswerInlineQuery(ctx, q.ID, inlineResponse, page.NextCursor)
+}
+
+// inlineFilter returns the query to search and the filter of its results.
+// Inline results are shown as a photo grid unless the query asks for a media
+// type with a type: filter or with the ! prefix standing for type:video.
+func inlineFilter(query string) (string, models.MemeFilter) {
+	if rest, ok := strings.CutPrefix(query, "!"); ok {
+		return rest, models.MemeFilter{MediaType: models.MediaTypeVideo}
+	}
+	// parts of the query with even indexes are outside of quoted phrases
+	for i, part := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			continue
+		}
+		for _, w := range strings.Fields(part) {
+			if strings.HasPrefix(strings.TrimPrefix(w, "-"), "type:") {
+				return query, models.MemeFilter{}
+			}
+		}
+	}
+	return query, models.MemeFilter{MediaType: models.MediaTypePhoto}
+}
+
+// processChosenInline reports the meme sent from inline results, so that
+// memes which are actually used rank higher. Result IDs are meme IDs.
+func processChosenInline(c *tgbotapi.ChosenInlineResult, r RequestContext) {
+	if strings.HasPrefix(c.ResultID, suggestionResultID) {
+		return
+	}
+	err := r.ApiClient.PostMemeUsage(r.Ctx, models.MemeID(c.ResultID), models.UsageSourceInline)
+	if err != nil {
+		slog.ErrorContext(r.Ctx, "Can't post meme usage", "err", err)
+	}
 }
 
-var ErrSkipped = errors.New("skip")
+// suggestionResultID is the ID of the inline result suggesting another query.
+// Other results are identified by meme IDs.
+const suggestionResultID = "suggestion"
 
-func prepareMeme(meme models.Meme, r RequestContext, filter telegram.CachedMediaType) (any, error) {
+// prepareSuggestion returns a result with a button searching the corrected
+// or completed query, ok is false when there is nothing to suggest.
+func prepareSuggestion(r RequestContext, query string) (any, bool) {
+	sug, err := r.ApiClient.SuggestQueries(r.Ctx, query, 1)
+	if err != nil {
+		slog.ErrorContext(r.Ctx, "Can't suggest queries", "err", err)
+		return nil, false
+	}
+	text := sug.DidYouMean
+	if text == "" && len(sug.Completions) > 0 && sug.Completions[0] != query {
+		text = sug.Completions[0]
+	}
+	if text == "" {
+		return nil, false
+	}
+
+	article := tgbotapi.NewInlineQueryResultArticle(suggestionResultID, fmt.Sprintf("Nothing found. Did you mean: %s?", text), text)
+	article.Description = "Send it and press its button to search"
+	markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.InlineKeyboardButton{Text: "Search " + text, SwitchInlineQueryCurrentChat: &text},
+	))
+	article.ReplyMarkup = &markup
+	return article, true
+}
+
+func prepareMeme(meme models.Meme, r RequestContext) (any, error) {
 	ctx := r.Ctx
 
 	cm, err := r.Bot.Upload(ctx, string(meme.ID), false, func() (telegram.UploadEntry, error) {
@@ -70,9 +115,6 @@ func prepareMeme(meme models.Meme, r RequestContext, filter telegram.CachedMedia
 	if err != nil {
 		return nil, fmt.Errorf("can't get file id: %w", err)
 	}
-	if cm.Type != filter {
-		return nil, ErrSkipped
-	}
 	switch cm.Type {
 	case telegram.CMPhoto:
 		photo := tgbotapi.NewInlineQueryResultCachedPhoto(string(meme.ID), cm.FileID)
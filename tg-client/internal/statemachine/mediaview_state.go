@@ -5,7 +5,11 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
 	"tg-client/internal/telegram"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 var _ State = &MediaViewState{}
@@ -16,8 +20,18 @@ type MediaViewState struct {
 	skip      bool
 }
 
+const (
+	// sendCallbackPrefix prefixes data of buttons sending a shown meme by
+	// itself, the meme ID follows it.
+	sendCallbackPrefix = "send:"
+	sendButtonsInRow   = 5
+)
+
 // Process implements State.
 func (m *MediaViewState) Process(r RequestContext) (State, error) {
+	if isCallback(r, sendCallbackPrefix) {
+		return m, sendChosen(r)
+	}
 	if !m.skip {
 		if r.Event.Message == nil {
 			return m, nil
@@ -40,10 +54,55 @@ func (m *MediaViewState) Process(r RequestContext) (State, error) {
 		return &CentralState{}, fmt.Errorf("can't get medias: %w", err)
 	}
 	sendMemes(r, memes)
+	offerSend(r, memes)
 
 	return m, nil
 }
 
+// offerSend shows buttons sending every shown meme by itself, so that it is
+// easy to forward and reported as used from search results.
+func offerSend(r RequestContext, memes []models.ScoredMeme) {
+	if len(memes) == 0 {
+		return
+	}
+	row := []tgbotapi.InlineKeyboardButton{}
+	for i, m := range memes {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(strconv.Itoa(i+1), sendCallbackPrefix+string(m.Meme.ID)))
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{row}
+	if len(row) > sendButtonsInRow {
+		rows = [][]tgbotapi.InlineKeyboardButton{row[:sendButtonsInRow], row[sendButtonsInRow:]}
+	}
+	_, err := r.Bot.SendMessage(r.Ctx, r.MustChat(), "Press a number to get the meme to send:", nil, nil, tgbotapi.NewInlineKeyboardMarkup(rows...))
+	if err != nil {
+		slog.ErrorContext(r.Ctx, "Can't offer sending memes", "err", err)
+	}
+}
+
+// sendChosen sends the meme of the pressed button and reports its usage.
+func sendChosen(r RequestContext) error {
+	ctx := r.Ctx
+	q := r.Event.CallbackQuery
+	id := models.MemeID(strings.TrimPrefix(q.Data, sendCallbackPrefix))
+
+	cm, err := uploadMeme(r, id)
+	if err != nil {
+		r.Bot.AnswerCallback(ctx, q.ID, "Can't send the meme")
+		return err
+	}
+	_, err = r.Bot.SendMedia(ctx, r.MustChat(), cm, "")
+	if err != nil {
+		r.Bot.AnswerCallback(ctx, q.ID, "Can't send the meme")
+		return fmt.Errorf("can't send meme: %w", err)
+	}
+	r.Bot.AnswerCallback(ctx, q.ID, "")
+	err = r.ApiClient.PostMemeUsage(ctx, id, models.UsageSourceSearch)
+	if err != nil {
+		slog.ErrorContext(ctx, "Can't post meme usage", "err", err)
+	}
+	return nil
+}
+
 func sendMemes(r RequestContext, memes []models.ScoredMeme) {
 	if len(memes) == 0 {
 		r.SendMessage("No more memes")
@@ -62,19 +121,27 @@ func sendMemes(r RequestContext, memes []models.ScoredMeme) {
 }
 
 func prepareMemeMediaGroup(r RequestContext, m models.ScoredMeme) (telegram.MediaGroupEntry, error) {
-	ctx := r.Ctx
-
 	meme := m.Meme
 	caption := fmt.Sprintf("ID:%s\nScore:%v\nBoard:%s\nDesc:%s", meme.ID, m.Score, meme.BoardID, meme.Descriptions)
-	cm, err := r.Bot.Upload(ctx, string(meme.ID), false, func() (telegram.UploadEntry, error) {
-		media, err := r.ApiClient.GetMediaByID(ctx, models.MediaID(meme.ID))
+	cm, err := uploadMeme(r, meme.ID)
+	if err != nil {
+		return telegram.MediaGroupEntry{}, err
+	}
+	return telegram.MediaGroupEntry{Media: cm, Caption: caption}, nil
+}
+
+// uploadMeme returns the uploaded media of the meme.
+func uploadMeme(r RequestContext, id models.MemeID) (telegram.CachedMedia, error) {
+	ctx := r.Ctx
+	cm, err := r.Bot.Upload(ctx, string(id), false, func() (telegram.UploadEntry, error) {
+		media, err := r.ApiClient.GetMediaByID(ctx, models.MediaID(id))
 		if err != nil {
 			return telegram.UploadEntry{}, fmt.Errorf("can't get media: %w", err)
 		}
 		return telegram.UploadEntry{Name: "file", Body: &media.Body}, nil
 	})
 	if err != nil {
-		return telegram.MediaGroupEntry{}, fmt.Errorf("can't get media: %v", err)
+		return telegram.CachedMedia{}, fmt.Errorf("can't get media: %v", err)
 	}
-	return telegram.MediaGroupEntry{Media: cm, Caption: caption}, nil
+	return cm, nil
 }
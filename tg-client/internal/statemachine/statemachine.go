@@ -101,6 +101,9 @@ func (s *Statemachine) processUpdate(ctx context.Context, u tgbotapi.Update) {
 	if q := u.InlineQuery; q != nil {
 		processInline(q, r)
 	}
+	if c := u.ChosenInlineResult; c != nil {
+		processChosenInline(c, r)
+	}
 }
 
 func logUpdate(ctx context.Context, u tgbotapi.Update) {
@@ -104,6 +104,29 @@ func (b *MSBot) SendMediaGroup(ctx context.Context, chatID int64, entries []Medi
 	return ids, nil
 }
 
+// SendMedia sends an uploaded photo or video by itself.
+func (b *MSBot) SendMedia(ctx context.Context, chatID int64, media CachedMedia, caption string) (int, error) {
+	var msg tgbotapi.Chattable
+	file := tgbotapi.FileID(media.FileID)
+	switch media.Type {
+	case CMPhoto:
+		photo := tgbotapi.NewPhoto(chatID, file)
+		photo.Caption = caption
+		msg = photo
+	case CMVideo:
+		video := tgbotapi.NewVideo(chatID, file)
+		video.Caption = caption
+		msg = video
+	default:
+		return 0, fmt.Errorf("unexpected media type: %s", media.Type)
+	}
+	m, err := b.bot.Send(msg)
+	if err != nil {
+		return 0, fmt.Errorf("can't send media: %w", err)
+	}
+	return m.MessageID, nil
+}
+
 func (b *MSBot) GetFile(ctx context.Context, message *tgbotapi.Message) (name string, body []byte, err error) {
 	var fileID string
 	if len(message.Photo) > 0 {
@@ -183,6 +206,15 @@ func (b *MSBot) Upload(ctx context.Context, key string, forceUpload bool, getUpl
 
 	return ph, nil
 }
+
+// AnswerCallback stops the progress of a pressed inline button showing text to the user.
+func (b *MSBot) AnswerCallback(ctx context.Context, callbackID string, text string) error {
+	_, err := b.bot.Request(tgbotapi.NewCallback(callbackID, text))
+	if err != nil {
+		return fmt.Errorf("can't answer callback: %w", err)
+	}
+	return nil
+}
 func (b *MSBot) DeleteMessage(ctx context.Context, chatID int64, msgID int) (err error) {
 	msg := tgbotapi.NewDeleteMessage(chatID, msgID)
 	_, err = b.bot.Send(msg)